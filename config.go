@@ -0,0 +1,79 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Config is an in-memory view of a .env file for applications that read
+// configuration frequently at runtime. The file is parsed once, like Parse,
+// and lookups are served from memory instead of re-reading the file:
+//
+//	cfg, err := env.NewConfig(".env")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	port, err := cfg.GetInt("PORT")
+//
+// A Config does not modify the process environment and is safe for
+// concurrent use.
+type Config struct {
+	filename string
+
+	mu   sync.RWMutex
+	vars map[string]string
+}
+
+// NewConfig returns a Config holding the key/value pairs of filename.
+//
+// Returns an error if the file cannot be opened or read.
+func NewConfig(filename string) (*Config, error) {
+	vars, err := Parse(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{filename: filename, vars: vars}, nil
+}
+
+// Get returns the value of key and reports whether it is present.
+func (c *Config) Get(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.vars[key]
+	return value, ok
+}
+
+// GetInt returns the value of key parsed as a base-10 integer.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, or an error
+// naming the key and value if it is not a valid integer.
+func (c *Config) GetInt(key string) (int, error) {
+	value, ok := c.Get(key)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("key %s: cannot parse %q as int", key, value)
+	}
+	return n, nil
+}
+
+// Reload parses the file again and replaces the held values in one step, so
+// concurrent readers see either the old or the new values, never a mix.
+//
+// Returns an error if the file cannot be opened or read; the previous values
+// are kept in that case.
+func (c *Config) Reload() error {
+	vars, err := Parse(c.filename)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vars = vars
+	return nil
+}
@@ -0,0 +1,78 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	filename, err := createTempEnvFile(`CONFIG_HOST=localhost
+CONFIG_PORT=5432
+CONFIG_URL=${CONFIG_HOST}:${CONFIG_PORT}
+CONFIG_INVALID=abc
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	cfg, err := NewConfig(filename)
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+
+	if got, ok := cfg.Get("CONFIG_URL"); !ok || got != "localhost:5432" {
+		t.Errorf("Get(CONFIG_URL) = %q, %v, want localhost:5432, true", got, ok)
+	}
+	if _, ok := cfg.Get("CONFIG_MISSING"); ok {
+		t.Error("Get(CONFIG_MISSING) reported present")
+	}
+	if got, err := cfg.GetInt("CONFIG_PORT"); err != nil || got != 5432 {
+		t.Errorf("GetInt(CONFIG_PORT) = %v, %v, want 5432, nil", got, err)
+	}
+	if _, err := cfg.GetInt("CONFIG_INVALID"); err == nil {
+		t.Error("GetInt(CONFIG_INVALID) expected error")
+	}
+	if _, err := cfg.GetInt("CONFIG_MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetInt(CONFIG_MISSING) error = %v, want %v", err, ErrNotFound)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cfg.Get("CONFIG_HOST")
+			}
+		}()
+	}
+	if err := os.WriteFile(filename, []byte("CONFIG_HOST=example.com\n"), 0600); err != nil {
+		t.Fatalf("failed to rewrite file: %v", err)
+	}
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	wg.Wait()
+
+	if got, _ := cfg.Get("CONFIG_HOST"); got != "example.com" {
+		t.Errorf("Get(CONFIG_HOST) after Reload() = %q, want example.com", got)
+	}
+	if _, ok := cfg.Get("CONFIG_PORT"); ok {
+		t.Error("Get(CONFIG_PORT) after Reload() reported present, want removed")
+	}
+
+	os.Remove(filename)
+	if err := cfg.Reload(); err == nil {
+		t.Error("Reload() expected error for removed file")
+	}
+	if got, _ := cfg.Get("CONFIG_HOST"); got != "example.com" {
+		t.Errorf("Get(CONFIG_HOST) after failed Reload() = %q, want example.com", got)
+	}
+
+	if _, err := NewConfig("non_existent_file.env"); err == nil {
+		t.Error("NewConfig() expected error for non-existent file")
+	}
+}
@@ -0,0 +1,38 @@
+package env
+
+// Diff parses two files and compares their contents. It returns the keys only
+// present in fileB (added), the keys only present in fileA (removed), and the
+// keys present in both with different values (changed). Added and changed map
+// to their values in fileB; removed maps to the value in fileA.
+//
+// All three maps are non-nil. Returns an error if either file cannot be opened
+// or read.
+func Diff(fileA, fileB string) (added, removed, changed map[string]string, err error) {
+	a, err := Parse(fileA)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	b, err := Parse(fileB)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	added = make(map[string]string)
+	removed = make(map[string]string)
+	changed = make(map[string]string)
+	for key, value := range b {
+		old, ok := a[key]
+		switch {
+		case !ok:
+			added[key] = value
+		case old != value:
+			changed[key] = value
+		}
+	}
+	for key, value := range a {
+		if _, ok := b[key]; !ok {
+			removed[key] = value
+		}
+	}
+	return added, removed, changed, nil
+}
@@ -0,0 +1,56 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	fileA, err := createTempEnvFile(`DB_HOST=localhost
+DB_PORT=5432
+DEBUG=true
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(fileA)
+
+	fileB, err := createTempEnvFile(`DB_HOST=db.example.com
+DB_PORT=5432
+LOG_LEVEL=info
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(fileB)
+
+	added, removed, changed, err := Diff(fileA, fileB)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	checks := []struct {
+		name string
+		got  map[string]string
+		want map[string]string
+	}{
+		{name: "added", got: added, want: map[string]string{"LOG_LEVEL": "info"}},
+		{name: "removed", got: removed, want: map[string]string{"DEBUG": "true"}},
+		{name: "changed", got: changed, want: map[string]string{"DB_HOST": "db.example.com"}},
+	}
+	for _, c := range checks {
+		if len(c.got) != len(c.want) {
+			t.Errorf("Diff() %s = %v, want %v", c.name, c.got, c.want)
+			continue
+		}
+		for key, val := range c.want {
+			if c.got[key] != val {
+				t.Errorf("Diff() %s[%s] = %q, want %q", c.name, key, c.got[key], val)
+			}
+		}
+	}
+
+	if _, _, _, err := Diff(fileA, "non_existent_file.env"); err == nil {
+		t.Error("Diff() expected error for non-existent file")
+	}
+}
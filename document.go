@@ -0,0 +1,211 @@
+package env
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// LineKind classifies the lines of a Document.
+type LineKind int
+
+// Line kinds distinguished by Document.
+const (
+	// LineBlank is an empty or whitespace-only line.
+	LineBlank LineKind = iota
+	// LineComment is a full-line comment.
+	LineComment
+	// LinePair is a KEY=VALUE definition, which may span several lines for
+	// multiline values.
+	LinePair
+	// LineOther is any other line, such as a malformed one. It is kept
+	// verbatim but otherwise ignored.
+	LineOther
+)
+
+// Line is a single entry of a Document.
+type Line struct {
+	Kind LineKind
+
+	// Raw is the original text of the line without its trailing newline.
+	// For a multiline value it holds all of the value's lines separated by
+	// "\n".
+	Raw string
+
+	// Key and Value hold the parsed key and unexpanded value of a LinePair.
+	// Changing Value makes Bytes replace just the value within Raw, while
+	// changing Key makes it write the line anew.
+	Key   string
+	Value string
+
+	quote       byte
+	parsedKey   string
+	parsedValue string
+}
+
+// Document is the full content of a .env file, including comments, blank
+// lines, and the original formatting of each pair. It suits config editors
+// and tools that make surgical edits: loading a file, changing a value, and
+// writing back the result with Bytes changes only the edited line.
+type Document struct {
+	Lines []Line
+
+	bom   bool
+	noEOL bool
+	crlf  bool
+}
+
+// LoadDocument reads filename into a Document. Values follow the same parsing
+// rules as GetEnv and are kept unexpanded.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadDocument(filename string) (*Document, error) {
+	file, err := openInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseDocument(data)
+}
+
+// parseDocument builds a Document from the content of a file.
+func parseDocument(data []byte) (*Document, error) {
+	pairs := make(map[int]entry)
+	err := lookupLoader.parseEntries(bytes.NewReader(data), func(e entry) error {
+		pairs[e.line] = e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	text := string(data)
+	if strings.HasPrefix(text, utf8BOM) {
+		doc.bom = true
+		text = strings.TrimPrefix(text, utf8BOM)
+	}
+	if text == "" {
+		return doc, nil
+	}
+	if strings.HasSuffix(text, "\n") {
+		text = strings.TrimSuffix(text, "\n")
+	} else {
+		doc.noEOL = true
+	}
+
+	lines := strings.Split(text, "\n")
+	doc.crlf = strings.HasSuffix(lines[0], "\r")
+	for i := 0; i < len(lines); i++ {
+		if e, ok := pairs[i+1]; ok {
+			end := valueEnd(lines, i)
+			doc.Lines = append(doc.Lines, Line{
+				Kind:        LinePair,
+				Raw:         strings.Join(lines[i:end+1], "\n"),
+				Key:         e.key,
+				Value:       e.value,
+				quote:       e.quote,
+				parsedKey:   e.key,
+				parsedValue: e.value,
+			})
+			i = end
+			continue
+		}
+
+		kind := LineOther
+		switch trimmed := strings.TrimSpace(lines[i]); {
+		case trimmed == "":
+			kind = LineBlank
+		case strings.HasPrefix(trimmed, "#"):
+			kind = LineComment
+		}
+		doc.Lines = append(doc.Lines, Line{Kind: kind, Raw: lines[i]})
+	}
+	return doc, nil
+}
+
+// Get returns the value of key and reports whether it is defined. When a key
+// is defined more than once the last definition wins, as with Parse.
+func (d *Document) Get(key string) (string, bool) {
+	for i := len(d.Lines) - 1; i >= 0; i-- {
+		if l := d.Lines[i]; l.Kind == LinePair && l.Key == key {
+			return l.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set changes the value of the last definition of key, leaving every other
+// line untouched, or appends a new pair if key is not defined. The edited
+// line keeps its indentation and inline comment, as described for Bytes, and
+// the quoting style of the original value when possible, as
+// described for MarshalOrdered. Like Get, Set works with unexpanded values:
+// unless the line is single-quoted, references in value are written as
+// references and expanded when the file is loaded.
+//
+// Returns an error if key is empty or contains "=", whitespace, or "#".
+func (d *Document) Set(key, value string) error {
+	if err := validateMarshalKey(key); err != nil {
+		return err
+	}
+	for i := len(d.Lines) - 1; i >= 0; i-- {
+		if l := &d.Lines[i]; l.Kind == LinePair && l.Key == key {
+			l.Value = value
+			return nil
+		}
+	}
+	d.Lines = append(d.Lines, Line{Kind: LinePair, Key: key, Value: value})
+	return nil
+}
+
+// Bytes serializes the document. Unchanged lines are written exactly as they
+// were read, including their line endings and a leading byte-order mark, so
+// only edited pairs differ from the original file. An edited value replaces
+// only the value text, keeping the indentation, key, and separator before it
+// and any inline comment after it; a pair whose key was changed is written
+// anew as a KEY=VALUE line. New pairs are written as KEY=VALUE lines using
+// the line ending of the first line of the file. Values are written raw and
+// quoted as needed.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	if d.bom {
+		buf.WriteString(utf8BOM)
+	}
+	for i, l := range d.Lines {
+		if i > 0 {
+			// The original last line may lack an ending when new pairs
+			// follow it.
+			if prev := d.Lines[i-1].Raw; d.crlf && l.Raw == "" && prev != "" && !strings.HasSuffix(prev, "\r") {
+				buf.WriteByte('\r')
+			}
+			buf.WriteByte('\n')
+		}
+		if l.Kind != LinePair || l.Raw != "" && l.Key == l.parsedKey && l.Value == l.parsedValue {
+			buf.WriteString(l.Raw)
+			continue
+		}
+		quoted := quoteRawAs(l.Value, l.quote)
+		if l.Raw != "" && l.Key == l.parsedKey {
+			if line, ok := spliceValue(l.Raw, quoted); ok {
+				buf.WriteString(line)
+				continue
+			}
+		}
+		buf.WriteString(l.Key)
+		buf.WriteByte('=')
+		buf.WriteString(quoted)
+		last := i == len(d.Lines)-1 && d.noEOL
+		if strings.HasSuffix(l.Raw, "\r") || l.Raw == "" && d.crlf && !last {
+			buf.WriteByte('\r')
+		}
+	}
+	if len(d.Lines) > 0 && !d.noEOL {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
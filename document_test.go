@@ -0,0 +1,242 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadDocument(t *testing.T) {
+	source := "# Database\r\n" +
+		"DB_HOST = localhost   # primary\r\n" +
+		"\r\n" +
+		"DB_PASS='secret'\r\n" +
+		"CERT=\"line1\r\nline2\"\r\n" +
+		"not a pair\r\n" +
+		"DB_HOST=override\r\n"
+	filename, err := createTempEnvFile(source)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	doc, err := LoadDocument(filename)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+
+	wantKinds := []LineKind{LineComment, LinePair, LineBlank, LinePair, LinePair, LineOther, LinePair}
+	if len(doc.Lines) != len(wantKinds) {
+		t.Fatalf("LoadDocument() returned %d lines, want %d", len(doc.Lines), len(wantKinds))
+	}
+	for i, kind := range wantKinds {
+		if doc.Lines[i].Kind != kind {
+			t.Errorf("Lines[%d].Kind = %v, want %v", i, doc.Lines[i].Kind, kind)
+		}
+	}
+	if got := doc.Lines[4].Value; got != "line1\nline2" {
+		t.Errorf("Lines[4].Value = %q, want %q", got, "line1\nline2")
+	}
+	if got, ok := doc.Get("DB_HOST"); !ok || got != "override" {
+		t.Errorf("Get(DB_HOST) = %q, %v, want override, true", got, ok)
+	}
+
+	if got := string(doc.Bytes()); got != source {
+		t.Errorf("Bytes() without edits = %q, want %q", got, source)
+	}
+
+	if err := doc.Set("DB_PASS", "new secret"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := doc.Set("CERT", "single"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := doc.Set("DB_PORT", "5432"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	want := "# Database\r\n" +
+		"DB_HOST = localhost   # primary\r\n" +
+		"\r\n" +
+		"DB_PASS='new secret'\r\n" +
+		"CERT=\"single\"\r\n" +
+		"not a pair\r\n" +
+		"DB_HOST=override\r\n" +
+		"DB_PORT=5432\r\n"
+	if got := string(doc.Bytes()); got != want {
+		t.Errorf("Bytes() after edits = %q, want %q", got, want)
+	}
+
+	if err := doc.Set("BAD KEY", "x"); err == nil {
+		t.Error("Set() expected error for invalid key")
+	}
+	if _, err := LoadDocument("non_existent_file.env"); err == nil {
+		t.Error("LoadDocument() expected error for non-existent file")
+	}
+
+	withStdin(t, filename, func() {
+		doc, err := LoadDocument("-")
+		if err != nil {
+			t.Fatalf("LoadDocument(\"-\") error = %v", err)
+		}
+		if got := string(doc.Bytes()); got != source {
+			t.Errorf("LoadDocument(\"-\").Bytes() = %q, want %q", got, source)
+		}
+	})
+}
+
+func TestDocumentSetReference(t *testing.T) {
+	filename, err := createTempEnvFile("DOCREF_BASE=http://h\nDOCREF_A=old\nDOCREF_B=\"x\"\nDOCREF_C='x'\n")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	doc, err := LoadDocument(filename)
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+	edits := map[string]string{
+		"DOCREF_A": "${DOCREF_BASE}/v2",
+		"DOCREF_B": "${DOCREF_BASE}/y",
+		"DOCREF_C": "$5",
+		"DOCREF_D": "$DOCREF_BASE z",
+	}
+	for key, value := range edits {
+		if err := doc.Set(key, value); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+	want := "DOCREF_BASE=http://h\n" +
+		"DOCREF_A=${DOCREF_BASE}/v2\n" +
+		"DOCREF_B=\"${DOCREF_BASE}/y\"\n" +
+		"DOCREF_C='$5'\n" +
+		"DOCREF_D=\"$DOCREF_BASE z\"\n"
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("Bytes() = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(filename, doc.Bytes(), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	got, err := Parse(filename)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	wantVars := map[string]string{
+		"DOCREF_A": "http://h/v2",
+		"DOCREF_B": "http://h/y",
+		"DOCREF_C": "$5",
+		"DOCREF_D": "http://h z",
+	}
+	for key, val := range wantVars {
+		if got[key] != val {
+			t.Errorf("Parse()[%s] = %q, want %q", key, got[key], val)
+		}
+	}
+}
+
+func TestDocumentSetKeepsLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		key    string
+		value  string
+		want   string
+	}{
+		{
+			name:   "indentation and comment",
+			source: "  PORT=5432 # default port\n",
+			key:    "PORT",
+			value:  "6000",
+			want:   "  PORT=6000 # default port\n",
+		},
+		{
+			name:   "spaced separator",
+			source: "HOST = localhost\t# primary\r\nNEXT=1\r\n",
+			key:    "HOST",
+			value:  "example.com",
+			want:   "HOST = example.com\t# primary\r\nNEXT=1\r\n",
+		},
+		{
+			name:   "quoted value",
+			source: "NAME=\"old name\" # display\n",
+			key:    "NAME",
+			value:  "new name",
+			want:   "NAME=\"new name\" # display\n",
+		},
+		{
+			name:   "comment directly after quote",
+			source: "NAME='old'#x\n",
+			key:    "NAME",
+			value:  "new",
+			want:   "NAME='new' #x\n",
+		},
+		{
+			name:   "multiline value",
+			source: "CERT=\"a\nb\" # pem\nNEXT=1\n",
+			key:    "CERT",
+			value:  "c",
+			want:   "CERT=\"c\" # pem\nNEXT=1\n",
+		},
+		{
+			name:   "new pair in CRLF file",
+			source: "A=1\r\n",
+			key:    "B",
+			value:  "2",
+			want:   "A=1\r\nB=2\r\n",
+		},
+		{
+			name:   "new pair in CRLF file without final newline",
+			source: "A=1\r\nC=3",
+			key:    "B",
+			value:  "2",
+			want:   "A=1\r\nC=3\r\nB=2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseDocument([]byte(tt.source))
+			if err != nil {
+				t.Fatalf("parseDocument() error = %v", err)
+			}
+			if err := doc.Set(tt.key, tt.value); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if got := string(doc.Bytes()); got != tt.want {
+				t.Errorf("Bytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentPreservesFraming(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "empty", source: ""},
+		{name: "byte-order mark", source: "\ufeffKEY=value\n"},
+		{name: "no final newline", source: "KEY=value\n# end"},
+		{name: "unterminated quote", source: "A=\"\nB=2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseDocument([]byte(tt.source))
+			if err != nil {
+				t.Fatalf("parseDocument() error = %v", err)
+			}
+			if got := string(doc.Bytes()); got != tt.source {
+				t.Errorf("Bytes() = %q, want %q", got, tt.source)
+			}
+		})
+	}
+
+	doc, err := parseDocument([]byte("A=\"\nB=2\n"))
+	if err != nil {
+		t.Fatalf("parseDocument() error = %v", err)
+	}
+	if got, ok := doc.Get("B"); len(doc.Lines) != 2 || !ok || got != "2" {
+		t.Errorf("parseDocument() with unterminated quote: %d lines, Get(B) = %q, %v; want 2 lines, 2, true", len(doc.Lines), got, ok)
+	}
+}
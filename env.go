@@ -17,11 +17,23 @@
 //	if err != nil {
 //		log.Fatal(err)
 //	}
+//
+// Functions that read a file use standard input when the filename is "-",
+// and transparently decompress files compressed with gzip, such as .env.gz.
 package env
 
 import (
-	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -32,6 +44,12 @@ import (
 // - Empty lines
 // - Quoted values (both single and double quotes)
 // - Basic KEY=VALUE format
+// - Variable interpolation with ${VAR} and $VAR
+//
+// References are expanded left to right using variables defined earlier in the
+// same file, falling back to the process environment. Unresolved references
+// expand to an empty string and "$$" yields a literal "$". Single-quoted values
+// are never expanded.
 //
 // Lines that don't conform to the KEY=VALUE format are silently skipped.
 //
@@ -42,40 +60,616 @@ import (
 //	DB_PORT=5432
 //	APP_NAME="My Application"
 //	API_KEY='secret-key'
+//	API_URL=${DB_HOST}/api
+//
+// Following the Unix convention, the filename "-" reads from standard input.
+// The same applies to every function in this package that reads a file.
 //
 // Returns an error if the file cannot be opened or read.
 func LoadEnv(filename string) error {
-	file, err := os.Open(filename)
+	return defaultLoader.Load(filename)
+}
+
+// MustLoadEnv is like LoadEnv but panics if the file cannot be loaded. It is
+// intended for small programs and tests where a missing configuration file
+// is genuinely fatal.
+func MustLoadEnv(filename string) {
+	if err := LoadEnv(filename); err != nil {
+		panic(fmt.Errorf("env: loading %s: %w", filename, err))
+	}
+}
+
+// LoadEnvFromReader reads environment variables from r and sets them in the
+// environment. It applies the same parsing rules as LoadEnv, which makes it
+// suitable for embedded files, network streams, or in-memory buffers.
+//
+// Returns an error if r cannot be read.
+func LoadEnvFromReader(r io.Reader) error {
+	return defaultLoader.LoadReader(r)
+}
+
+// LoadEnvBytes parses data as .env content and sets the variables in the
+// environment. It applies the same parsing rules as LoadEnv, which is handy for
+// generated configuration and tests that should not touch the filesystem.
+//
+// Returns an error only if parsing fails.
+func LoadEnvBytes(data []byte) error {
+	return defaultLoader.LoadReader(bytes.NewReader(data))
+}
+
+// LoadEnvDryRun returns exactly the key/value pairs LoadEnv would set for the
+// given file, with references expanded, without modifying the process
+// environment. It is useful for previewing configuration in CI or when
+// debugging precedence. For custom rules such as Override set to false or
+// SkipEmpty, the DryRun method of a Loader returns the matching preview.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvDryRun(filename string) (map[string]string, error) {
+	return defaultLoader.DryRun(filename)
+}
+
+// LoadEnvKeys reads environment variables from a file like LoadEnv and returns
+// the keys it set, in the order they first appear in the file. Keys defined
+// more than once are listed only once. This is useful for startup logging and
+// for auditing which variables a file applied.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvKeys(filename string) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	err := defaultLoader.parseFile(filename, func(key, value string, _ int) error {
+		if err := setVar(key, value); err != nil {
+			return err
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// LoadEnvContext reads environment variables from a file like LoadEnv, but
+// aborts with the context's error once ctx is canceled or its deadline
+// passes. The context is checked before each read from the file and before
+// each variable is set, which bounds how long loading from a slow mount may
+// take. Variables set before cancellation remain set.
+//
+// Returns ctx.Err() if the context ends first, or an error if the file cannot
+// be opened or read.
+func LoadEnvContext(ctx context.Context, filename string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	file, err := openInput(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	r := &contextReader{ctx: ctx, r: file}
+	return defaultLoader.parse(r, func(key, value string, line int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return defaultLoader.setenv(key, value, line)
+	})
+}
+
+// LoadEnvOverload reads environment variables from a file like LoadEnv, but lets
+// the caller choose whether file values replace variables that are already set.
+// With override set to false, keys already present in the process environment
+// are left untouched so file values act as defaults only.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvOverload(filename string, override bool) error {
+	return (&Loader{Override: override, Expand: true}).Load(filename)
+}
+
+// LoadEnvStrict reads environment variables from a file like LoadEnv, but
+// returns an error instead of skipping lines that are not comments, empty, or
+// in KEY=VALUE format, or whose key is not a valid name according to
+// IsValidKey. The error includes the line number and the offending content or
+// key. No further variables are set once such a line is found.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvStrict(filename string) error {
+	return (&Loader{Override: true, Strict: true, Expand: true, ValidateKeys: true}).Load(filename)
+}
+
+// LoadEnvSkipInvalid reads environment variables from a file like LoadEnv, but
+// skips entries whose key is not a valid name according to IsValidKey instead
+// of passing them to os.Setenv.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvSkipInvalid(filename string) error {
+	return (&Loader{Override: true, Expand: true, ValidateKeys: true}).Load(filename)
+}
+
+// LoadEnvNoDuplicates reads environment variables from a file like LoadEnv, but
+// returns an error if any key is defined more than once. The error lists each
+// duplicated key with the line numbers of all its definitions, which helps
+// catch copy-paste mistakes and merge-conflict leftovers. Nothing is set in the
+// environment when duplicates are found.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvNoDuplicates(filename string) error {
+	type pair struct{ key, value string }
+	var pairs []pair
+	lines := make(map[string][]int)
+	var order []string
+
+	err := defaultLoader.parseFile(filename, func(key, value string, line int) error {
+		if _, seen := lines[key]; !seen {
+			order = append(order, key)
 		}
+		lines[key] = append(lines[key], line)
+		pairs = append(pairs, pair{key, value})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
+	var dups []string
+	for _, key := range order {
+		if len(lines[key]) < 2 {
 			continue
 		}
+		nums := make([]string, len(lines[key]))
+		for i, n := range lines[key] {
+			nums[i] = strconv.Itoa(n)
+		}
+		dups = append(dups, fmt.Sprintf("%s (lines %s)", key, strings.Join(nums, ", ")))
+	}
+	if len(dups) > 0 {
+		return fmt.Errorf("duplicate keys: %s", strings.Join(dups, "; "))
+	}
+
+	for _, p := range pairs {
+		if err := setVar(p.key, p.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadEnvFileRefs reads environment variables from a file like LoadEnv, but
+// treats keys ending in _FILE as references to secret files, following the
+// Docker secrets convention. For a line such as
+//
+//	DB_PASSWORD_FILE=/run/secrets/db_pass
+//
+// the referenced file is read and DB_PASSWORD is set to its contents with
+// surrounding whitespace trimmed; DB_PASSWORD_FILE itself is not set. Relative
+// paths are resolved against the working directory.
+//
+// Returns an error if the file cannot be opened or read, or if a referenced
+// file cannot be read. The error names the key and the referenced path.
+func LoadEnvFileRefs(filename string) error {
+	return (&Loader{Override: true, Expand: true, FileRefs: true}).Load(filename)
+}
+
+// LoadEnvWithSeparator reads environment variables from a file like LoadEnv,
+// but splits keys from values at sep instead of "=". Only the first occurrence
+// of sep on a line is used, so separators inside values are preserved. This
+// allows parsing legacy files written as "KEY: value".
+//
+// Returns an error if sep is empty or the file cannot be opened or read.
+func LoadEnvWithSeparator(filename string, sep string) error {
+	if sep == "" {
+		return errors.New("separator must not be empty")
+	}
+	return (&Loader{Override: true, Expand: true, Separator: sep}).Load(filename)
+}
+
+// LoadEnvWithComment reads environment variables from a file like LoadEnv, but
+// recognizes prefix instead of "#" as the comment marker, for example ";" in
+// files produced by .ini-style tooling. The marker applies to both full-line
+// comments and inline comments after a value.
+//
+// Returns an error if prefix is empty or the file cannot be opened or read.
+func LoadEnvWithComment(filename string, prefix string) error {
+	if prefix == "" {
+		return errors.New("comment prefix must not be empty")
+	}
+	return (&Loader{Override: true, Expand: true, CommentPrefix: prefix}).Load(filename)
+}
+
+// LoadEnvPrefix reads environment variables from a file like LoadEnv, but only
+// sets keys that start with prefix. When strip is true the prefix is removed
+// from each key before it is set, so AUTH_SECRET loaded with prefix "AUTH_"
+// becomes SECRET. Keys that would be empty after stripping are skipped.
+// References in values may still point to keys without the prefix.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvPrefix(filename, prefix string, strip bool) error {
+	return defaultLoader.parseFile(filename, func(key, value string, _ int) error {
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		if strip {
+			key = strings.TrimPrefix(key, prefix)
+			if key == "" {
+				return nil
+			}
+		}
+		return setVar(key, value)
+	})
+}
+
+// LoadEnvFilter reads environment variables from a file like LoadEnv, but only
+// sets the pairs for which keep returns true. keep is called once per pair in
+// file order with the parsed, expanded value. Skipped pairs can still be
+// referenced by later values in the file.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvFilter(filename string, keep func(key, value string) bool) error {
+	return defaultLoader.parseFile(filename, func(key, value string, _ int) error {
+		if !keep(key, value) {
+			return nil
+		}
+		return setVar(key, value)
+	})
+}
+
+// LoadEnvAllowlist reads environment variables from a file like LoadEnv, but
+// only sets keys listed in allowed, so a mistaken or malicious file cannot
+// change anything else, such as PATH. Keys not in allowed are silently
+// skipped; use LoadEnvAllowlistStrict to reject the file instead. Keys are
+// matched exactly, after any parsing rules have been applied.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvAllowlist(filename string, allowed []string) error {
+	set := keySet(allowed)
+	return LoadEnvFilter(filename, func(key, _ string) bool {
+		return set[key]
+	})
+}
+
+// LoadEnvAllowlistStrict is like LoadEnvAllowlist, but a file defining any key
+// not in allowed is rejected. Nothing is set in the environment when such keys
+// are found.
+//
+// Returns an error listing the disallowed keys, or an error if the file cannot
+// be opened or read.
+func LoadEnvAllowlistStrict(filename string, allowed []string) error {
+	set := keySet(allowed)
+	return loadEnvRejecting(filename, "disallowed keys", func(key string) bool {
+		return !set[key]
+	})
+}
+
+// defaultDenylist holds the keys returned by DefaultDenylist.
+var defaultDenylist = []string{
+	"PATH", "HOME", "USER", "SHELL", "IFS",
+	"LD_PRELOAD", "LD_LIBRARY_PATH", "LD_AUDIT",
+	"DYLD_INSERT_LIBRARIES", "DYLD_LIBRARY_PATH",
+}
+
+// DefaultDenylist returns a set of keys that env files should rarely be
+// allowed to change: the command search path, the user's identity and shell,
+// and the dynamic loader variables that can inject code into every process
+// started afterwards. Pass it to LoadEnvDenylist, or append to it for
+// application-specific keys. A new slice is returned on every call.
+func DefaultDenylist() []string {
+	return append([]string(nil), defaultDenylist...)
+}
+
+// LoadEnvDenylist reads environment variables from a file like LoadEnv, but
+// never sets keys listed in blocked, protecting critical variables such as
+// PATH or LD_PRELOAD from being tampered with. Blocked keys are silently
+// skipped; use LoadEnvDenylistStrict to reject the file instead. Keys are
+// matched exactly, except on Windows, where variable names are
+// case-insensitive and are matched ignoring case.
+//
+// Returns an error if the file cannot be opened or read.
+func LoadEnvDenylist(filename string, blocked []string) error {
+	isBlocked := denylistMatcher(blocked)
+	return LoadEnvFilter(filename, func(key, _ string) bool {
+		return !isBlocked(key)
+	})
+}
+
+// LoadEnvDenylistStrict is like LoadEnvDenylist, but a file defining any
+// blocked key is rejected. Nothing is set in the environment when such keys
+// are found.
+//
+// Returns an error listing the blocked keys, or an error if the file cannot be
+// opened or read.
+func LoadEnvDenylistStrict(filename string, blocked []string) error {
+	return loadEnvRejecting(filename, "blocked keys", denylistMatcher(blocked))
+}
+
+// denylistMatcher returns a function reporting whether a key is in blocked,
+// ignoring case on Windows.
+func denylistMatcher(blocked []string) func(key string) bool {
+	if runtime.GOOS != "windows" {
+		set := keySet(blocked)
+		return func(key string) bool { return set[key] }
+	}
+	set := make(map[string]bool, len(blocked))
+	for _, key := range blocked {
+		set[strings.ToUpper(key)] = true
+	}
+	return func(key string) bool { return set[strings.ToUpper(key)] }
+}
+
+// keySet returns keys as a set.
+func keySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
+
+// loadEnvRejecting parses filename with the LoadEnv rules and sets every pair
+// unless reject reports true for any key, in which case nothing is set and the
+// error lists the rejected keys in file order after the given description.
+func loadEnvRejecting(filename, what string, reject func(key string) bool) error {
+	type pair struct{ key, value string }
+	var pairs []pair
+	var rejected []string
+	seen := make(map[string]bool)
+
+	err := defaultLoader.parseFile(filename, func(key, value string, _ int) error {
+		if reject(key) {
+			if !seen[key] {
+				seen[key] = true
+				rejected = append(rejected, key)
+			}
+			return nil
+		}
+		pairs = append(pairs, pair{key, value})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("%s: %s", what, strings.Join(rejected, ", "))
+	}
+
+	for _, p := range pairs {
+		if err := setVar(p.key, p.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnsetEnv parses the given file and removes every key it defines from the
+// process environment. It reverses the effect of LoadEnv, which makes tests
+// that load configuration easy to clean up. Keys that are already unset are
+// ignored.
+//
+// Returns an error if the file cannot be opened or read.
+func UnsetEnv(filename string) error {
+	return lookupLoader.parseFile(filename, func(key, _ string, _ int) error {
+		return unsetVar(key)
+	})
+}
+
+// Parse reads the given file and returns its key/value pairs without modifying
+// the process environment. It follows the same parsing rules as LoadEnv; when a
+// key appears more than once the last value wins.
+//
+// Returns an error if the file cannot be opened or read.
+func Parse(filename string) (map[string]string, error) {
+	return defaultLoader.Parse(filename)
+}
+
+// ParseInto reads the given file like Parse and writes its key/value pairs
+// into dst, overwriting existing entries. Calling it once per file
+// accumulates layered configuration in a single map, with later files taking
+// precedence. References in values resolve against the file being parsed and
+// the process environment, not against entries already in dst.
+//
+// Returns an error if dst is nil or the file cannot be opened or read. Pairs
+// parsed before a read error may already have been written to dst.
+func ParseInto(filename string, dst map[string]string) error {
+	if dst == nil {
+		return errors.New("ParseInto: nil destination map")
+	}
+	return defaultLoader.parseFile(filename, func(key, value string, _ int) error {
+		dst[key] = value
+		return nil
+	})
+}
+
+// KeyValue is a single key/value pair parsed from a file.
+type KeyValue struct {
+	Key   string
+	Value string
+	// Quote is the quote character that surrounded the value in the file,
+	// '"' or '\'', or 0 if the value was unquoted. MarshalOrdered uses it to
+	// keep the original quoting style.
+	Quote byte
+	// Comment holds the text of a full-line comment, including its marker,
+	// for entries returned by ParseReaderOrderedComments. Key and Value are
+	// empty for such entries.
+	Comment string
+}
+
+// ParseOrdered reads the given file like Parse, but returns the pairs as a
+// slice in file order. Keys that appear more than once are included each time
+// they are encountered, and each pair records how its value was quoted. Values
+// are expanded; use ParseOrderedRaw to rewrite a file with MarshalOrdered.
+//
+// Returns an error if the file cannot be opened or read.
+func ParseOrdered(filename string) ([]KeyValue, error) {
+	var pairs []KeyValue
+	if err := defaultLoader.parseFileEntries(filename, collectOrdered(&pairs)); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// ParseOrderedRaw behaves like ParseOrdered but returns values unexpanded, as
+// written in the file, so ${VAR} and $VAR references are kept. Together with
+// MarshalOrdered it allows order- and style-preserving round-trips that
+// neither resolve references nor copy the process environment into the file.
+//
+// Returns an error if the file cannot be opened or read.
+func ParseOrderedRaw(filename string) ([]KeyValue, error) {
+	var pairs []KeyValue
+	if err := lookupLoader.parseFileEntries(filename, collectOrdered(&pairs)); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// ParseReaderOrdered reads from r like ParseOrdered reads a file, returning
+// the pairs in order. This suits embedded files and in-memory buffers.
+//
+// Returns an error if r cannot be read.
+func ParseReaderOrdered(r io.Reader) ([]KeyValue, error) {
+	var pairs []KeyValue
+	if err := defaultLoader.parseEntries(r, collectOrdered(&pairs)); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// ParseReaderOrderedComments behaves like ParseReaderOrdered but also returns
+// each full-line comment, in place, as an entry whose Comment field holds the
+// comment text. Values are returned unexpanded, as for ParseOrderedRaw, so
+// passing the result to MarshalOrdered rewrites the comments along with the
+// pairs without resolving references. Blank lines and inline comments are not
+// retained.
+//
+// Returns an error if r cannot be read.
+func ParseReaderOrderedComments(r io.Reader) ([]KeyValue, error) {
+	l := *lookupLoader
+	l.keepComments = true
+
+	var pairs []KeyValue
+	if err := l.parseEntries(r, collectOrdered(&pairs)); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// collectOrdered returns a parseEntries callback that appends every entry to
+// pairs.
+func collectOrdered(pairs *[]KeyValue) func(e entry) error {
+	return func(e entry) error {
+		if e.comment {
+			*pairs = append(*pairs, KeyValue{Comment: e.value})
+			return nil
+		}
+		*pairs = append(*pairs, KeyValue{Key: e.key, Value: e.value, Quote: e.quote})
+		return nil
+	}
+}
+
+// ParseNested reads the given file like Parse and splits each key on "." into
+// nested maps, so server.port=8080 and server.host=localhost become
+// {"server": {"port": "8080", "host": "localhost"}}. This bridges flat env
+// files and consumers of structured configuration such as JSON encoders.
+// Leaf values are strings and intermediate levels are map[string]interface{}.
+//
+// Keys are applied in file order and the later definition wins when a flat
+// key and a nested one collide: server=x after server.port=8080 replaces the
+// server map with "x", while server.port=8080 after server=x replaces "x"
+// with a map.
+//
+// Returns an error if the file cannot be opened or read.
+func ParseNested(filename string) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	err := defaultLoader.parseFile(filename, func(key, value string, _ int) error {
+		parts := strings.Split(key, ".")
+		m := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := m[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				m[part] = child
+			}
+			m = child
+		}
+		m[parts[len(parts)-1]] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		value = strings.Trim(value, `"'`)
+// ToEnviron reads the given file like Parse and returns its pairs as
+// KEY=VALUE strings, the form expected by exec.Cmd.Env, so configuration can
+// be passed to a child process without loading it into the current one:
+//
+//	cmd := exec.Command("worker")
+//	cmd.Env, err = env.ToEnviron(".env")
+//
+// Keys are listed in the order they first appear in the file, each once with
+// its last value. Use ToEnvironMerged to include the current environment.
+//
+// Returns an error if the file cannot be opened or read.
+func ToEnviron(filename string) ([]string, error) {
+	return toEnviron(filename, nil)
+}
+
+// ToEnvironMerged behaves like ToEnviron but merges the file into
+// os.Environ(). The result starts with the current environment in its
+// original order; variables also defined in the file take the file's value
+// in place, as LoadEnv would set them, and keys only found in the file follow
+// in file order. Each key appears exactly once.
+//
+// Returns an error if the file cannot be opened or read.
+func ToEnvironMerged(filename string) ([]string, error) {
+	return toEnviron(filename, os.Environ())
+}
 
-		os.Setenv(key, value)
+// toEnviron implements ToEnviron and ToEnvironMerged, merging the file into
+// base.
+func toEnviron(filename string, base []string) ([]string, error) {
+	var keys []string
+	vars := make(map[string]string)
+	err := defaultLoader.parseFile(filename, func(key, value string, _ int) error {
+		if _, seen := vars[key]; !seen {
+			keys = append(keys, key)
+		}
+		vars[key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return scanner.Err()
+	environ := make([]string, 0, len(base)+len(keys))
+	written := make(map[string]bool)
+	for _, kv := range base {
+		key, _, _ := strings.Cut(kv, "=")
+		if value, ok := vars[key]; ok {
+			if written[key] {
+				continue
+			}
+			kv = key + "=" + value
+		}
+		written[key] = true
+		environ = append(environ, kv)
+	}
+	for _, key := range keys {
+		if !written[key] {
+			environ = append(environ, key+"="+vars[key])
+		}
+	}
+	return environ, nil
 }
 
 // GetEnv retrieves the value of a specific environment variable from the given file.
 // It follows the same parsing rules as LoadEnv but only returns the value for the
-// specified key.
+// specified key. Variable references in the value are returned unexpanded;
+// use the Get method of a Loader with Expand set to resolve them.
 //
 // The function will:
 // - Skip comment lines (starting with #)
@@ -83,32 +677,267 @@ func LoadEnv(filename string) error {
 // - Remove surrounding quotes (both single and double) from values
 // - Return the first matching value if the key appears multiple times
 //
+// The filename "-" reads from standard input, as with LoadEnv.
+//
 // If the key is not found, it returns an empty string and nil error.
+// Use LookupEnv to distinguish a missing key from an empty value.
 // Returns an error only if the file cannot be opened or read.
 func GetEnv(key string, filename string) (string, error) {
-	file, err := os.Open(filename)
+	value, _, err := LookupEnv(key, filename)
+	return value, err
+}
+
+// GetEnvDefault retrieves the value of key from the given file like GetEnv, but
+// returns fallback when the key is not present. A key explicitly set to an
+// empty string is returned as-is rather than replaced by the fallback.
+//
+// Returns an error only if the file cannot be opened or read.
+func GetEnvDefault(key, filename, fallback string) (string, error) {
+	value, found, err := LookupEnv(key, filename)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
+	if !found {
+		return fallback, nil
+	}
+	return value, nil
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+// LookupEnv retrieves the value of key from the given file like GetEnv and
+// reports whether the key was present, mirroring os.LookupEnv. This lets
+// callers tell a missing key apart from one set to an empty string.
+//
+// Returns an error only if the file cannot be opened or read.
+func LookupEnv(key, filename string) (string, bool, error) {
+	return lookupLoader.Lookup(key, filename)
+}
+
+// GetAll returns every key/value pair in the given file without modifying the
+// process environment. It is the multi-key counterpart of GetEnv: values are
+// returned unexpanded, unlike Parse, which expands references the way LoadEnv
+// does. When a key appears more than once the last value wins, mirroring how
+// a shell would evaluate the file.
+//
+// Returns an error if the file cannot be opened or read.
+func GetAll(filename string) (map[string]string, error) {
+	return lookupLoader.Parse(filename)
+}
+
+// GetMany retrieves the values of several keys from the given file in a
+// single pass, which avoids the repeated reads of calling GetEnv once per key.
+// Like GetEnv, values are returned unexpanded and the first definition of a
+// key wins. Keys not found in the file are absent from the returned map.
+//
+// Returns an error if the file cannot be opened or read.
+func GetMany(filename string, keys ...string) (map[string]string, error) {
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	vars := make(map[string]string, len(keys))
+	err := lookupLoader.parseFile(filename, func(key, value string, _ int) error {
+		if _, seen := vars[key]; !wanted[key] || seen {
+			return nil
 		}
+		vars[key] = value
+		if len(vars) == len(wanted) {
+			return errStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
+// GetEnvLine retrieves the value of key from the given file like GetEnv and
+// also returns the 1-based line number where the key is defined, which helps
+// track down a wrong value in a large file. For multiline values the line of
+// the key is returned. If the key is not found, the line is 0.
+//
+// Returns an error only if the file cannot be opened or read.
+func GetEnvLine(key, filename string) (value string, line int, err error) {
+	err = lookupLoader.parseFile(filename, func(k, v string, n int) error {
+		if k != key {
+			return nil
+		}
+		value, line = v, n
+		return errStop
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return value, line, nil
+}
+
+// GetEnvFold retrieves the value of key from the given file like GetEnv, but
+// matches keys case-insensitively using strings.EqualFold. If several keys
+// differ only in case, the first one in the file wins.
+//
+// If the key is not found, it returns an empty string and nil error.
+// Returns an error only if the file cannot be opened or read.
+func GetEnvFold(key, filename string) (string, error) {
+	var value string
+	err := lookupLoader.parseFile(filename, func(k, v string, _ int) error {
+		if !strings.EqualFold(k, key) {
+			return nil
+		}
+		value = v
+		return errStop
+	})
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// GetEnvChain retrieves the value of key from the first of filenames that
+// defines it, reading each file like GetEnv. Listing .env.local before .env
+// reads a single key with the override taking precedence, without loading
+// either file. Files that do not exist are skipped, and later files are not
+// read once the key is found.
+//
+// If no file defines the key, it returns an empty string and nil error.
+// Returns an error if a file exists but cannot be opened or read.
+func GetEnvChain(key string, filenames ...string) (string, error) {
+	for _, filename := range filenames {
+		value, found, err := LookupEnv(key, filename)
+		if errors.Is(err, fs.ErrNotExist) {
 			continue
 		}
+		if err != nil {
+			return "", err
+		}
+		if found {
+			return value, nil
+		}
+	}
+	return "", nil
+}
 
-		if strings.TrimSpace(parts[0]) == key {
-			value := strings.TrimSpace(parts[1])
-			return strings.Trim(value, `"'`), nil
+// GetEnvMap returns the key/value pairs in the given file whose keys start
+// with prefix, keyed by the rest of the key, so DB_HOST and DB_PORT read with
+// prefix "DB_" become HOST and PORT. This is convenient for building
+// sub-configurations. Keys not matching the prefix, and keys equal to it, are
+// excluded. Values are returned unexpanded like GetAll, and when a key appears
+// more than once the last value wins.
+//
+// Returns an error if the file cannot be opened or read.
+func GetEnvMap(prefix, filename string) (map[string]string, error) {
+	vars := make(map[string]string)
+	err := lookupLoader.parseFile(filename, func(key, value string, _ int) error {
+		if !strings.HasPrefix(key, prefix) || key == prefix {
+			return nil
 		}
+		vars[strings.TrimPrefix(key, prefix)] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// Keys returns the names of the keys defined in the given file without their
+// values, which suits tooling that audits the configuration surface without
+// exposing secrets. Keys are listed in the order they first appear in the
+// file; keys defined more than once are listed only once. The process
+// environment is not modified.
+//
+// Returns an error if the file cannot be opened or read.
+func Keys(filename string) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	err := lookupLoader.parseFile(filename, func(key, _ string, _ int) error {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Walk parses the given file and calls fn for each key/value pair in file
+// order, without modifying the process environment. Parsing follows the same
+// rules as LoadEnv. Walking stops at the first error returned by fn, and that
+// error is returned to the caller.
+//
+// Returns an error if the file cannot be opened or read.
+func Walk(filename string, fn func(key, value string) error) error {
+	return defaultLoader.parseFile(filename, func(key, value string, _ int) error {
+		return fn(key, value)
+	})
+}
+
+// LoadEnvFiles loads each file in order with LoadEnv, so keys in later files
+// override those set by earlier ones. This suits layered configuration such as
+// a base .env followed by a .env.local override.
+//
+// Returns the first error encountered; files after the failing one are not
+// loaded.
+func LoadEnvFiles(filenames ...string) error {
+	for _, filename := range filenames {
+		if err := LoadEnv(filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadEnvFilesOptional behaves like LoadEnvFiles but silently skips files that
+// do not exist. Other errors, such as permission problems, are still returned.
+func LoadEnvFilesOptional(filenames ...string) error {
+	for _, filename := range filenames {
+		if err := LoadEnv(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadEnvForProfile loads dir/.env and then dir/.env.<profile> with LoadEnv,
+// so keys in the profile file override the base file. This codifies the
+// common layout of a shared .env next to .env.development and
+// .env.production. The profile is usually taken from a variable:
+//
+//	err := env.LoadEnvForProfile(".", os.Getenv("APP_ENV"))
+//
+// A missing profile file is skipped, as is the profile step when profile is
+// empty. Returns an error if the base file is missing or either file cannot
+// be read.
+func LoadEnvForProfile(dir, profile string) error {
+	base := filepath.Join(dir, ".env")
+	if err := LoadEnv(base); err != nil {
+		return err
+	}
+	if profile == "" {
+		return nil
+	}
+	return LoadEnvFilesOptional(base + "." + profile)
+}
+
+// LoadEnvGlob loads every file matching pattern, as understood by
+// filepath.Glob, in sorted order. Later files override keys set by earlier
+// ones, so fragments such as conf.d/10-base.env and conf.d/20-local.env can be
+// ordered by name.
+//
+// Returns an error if the pattern is malformed, if no files match it, or if
+// any matching file cannot be loaded.
+func LoadEnvGlob(pattern string) error {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no files match %q", pattern)
 	}
 
-	return "", scanner.Err()
+	sort.Strings(matches)
+	return LoadEnvFiles(matches...)
 }
@@ -0,0 +1,118 @@
+package env
+
+import (
+	"os"
+	"strings"
+)
+
+// Expand replaces ${VAR} and $VAR references in s using the same rules as
+// LoadEnv, resolving them against vars first and then the process
+// environment. It allows interpolation outside of loading, for example
+// against variables returned by GetAll. A nil vars map resolves references
+// from the process environment only.
+func Expand(s string, vars map[string]string) string {
+	return expandValue(s, vars)
+}
+
+// expandValue replaces ${VAR} and $VAR references in s. References are
+// resolved against vars first and then the process environment; unresolved
+// references expand to an empty string. A literal "$$" collapses to "$".
+//
+// Braced references also accept the POSIX forms ${VAR:-default}, which yields
+// default when VAR is unset or empty, and ${VAR:+alt}, which yields alt when
+// VAR is set and non-empty and an empty string otherwise. The default and alt
+// words are used literally; nested references are not expanded.
+func expandValue(s string, vars map[string]string) string {
+	return expandRefs(s, vars, true, false)
+}
+
+// expandRefs implements expandValue, recognizing $ references when dollar is
+// set and %VAR% references when percent is set. A literal "%%" collapses to
+// "%" when percent is set; a % that does not start a reference to a valid key
+// is kept. Text is scanned once from left to right, so the result of one
+// reference is never expanded again.
+func expandRefs(s string, vars map[string]string, dollar, percent bool) string {
+	if !(dollar && strings.Contains(s, "$")) && !(percent && strings.Contains(s, "%")) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if percent && s[i] == '%' {
+			if i+1 < len(s) && s[i+1] == '%' {
+				b.WriteByte('%')
+				i++
+				continue
+			}
+			if end := strings.IndexByte(s[i+1:], '%'); end >= 0 && IsValidKey(s[i+1:i+1+end]) {
+				b.WriteString(lookupVar(s[i+1:i+1+end], vars))
+				i += end + 1
+				continue
+			}
+			b.WriteByte(s[i])
+			continue
+		}
+		if !dollar || s[i] != '$' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				b.WriteByte(s[i])
+				continue
+			}
+			b.WriteString(expandBraced(s[i+2:i+2+end], vars))
+			i += end + 2
+		case isNameStart(next):
+			j := i + 2
+			for j < len(s) && isNameChar(s[j]) {
+				j++
+			}
+			b.WriteString(lookupVar(s[i+1:j], vars))
+			i = j - 1
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// expandBraced resolves the contents of a ${...} reference, applying the
+// :- and :+ operators when present.
+func expandBraced(expr string, vars map[string]string) string {
+	if i := strings.Index(expr, ":-"); i >= 0 {
+		if value := lookupVar(expr[:i], vars); value != "" {
+			return value
+		}
+		return expr[i+2:]
+	}
+	if i := strings.Index(expr, ":+"); i >= 0 {
+		if lookupVar(expr[:i], vars) != "" {
+			return expr[i+2:]
+		}
+		return ""
+	}
+	return lookupVar(expr, vars)
+}
+
+// lookupVar resolves name against vars, falling back to the process environment.
+func lookupVar(name string, vars map[string]string) string {
+	if value, ok := vars[name]; ok {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
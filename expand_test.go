@@ -0,0 +1,100 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+func TestExpandValue(t *testing.T) {
+	os.Setenv("EXPAND_TEST_OS", "from-os")
+	defer os.Unsetenv("EXPAND_TEST_OS")
+
+	vars := map[string]string{"BASE": "http://localhost", "EMPTY": ""}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "no references", in: "plain", want: "plain"},
+		{name: "braced reference", in: "${BASE}/api", want: "http://localhost/api"},
+		{name: "bare reference", in: "$BASE/api", want: "http://localhost/api"},
+		{name: "process environment fallback", in: "${EXPAND_TEST_OS}", want: "from-os"},
+		{name: "unresolved reference", in: "a${EXPAND_TEST_MISSING}b", want: "ab"},
+		{name: "escaped dollar", in: "cost $$5", want: "cost $5"},
+		{name: "trailing dollar", in: "cost$", want: "cost$"},
+		{name: "unterminated brace", in: "${BASE", want: "${BASE"},
+		{name: "default for unset", in: "${EXPAND_TEST_MISSING:-8080}", want: "8080"},
+		{name: "default for empty", in: "${EMPTY:-8080}", want: "8080"},
+		{name: "default ignored when set", in: "${BASE:-other}", want: "http://localhost"},
+		{name: "alternate when set", in: "${BASE:+set}", want: "set"},
+		{name: "alternate for unset", in: "${EXPAND_TEST_MISSING:+set}", want: ""},
+		{name: "alternate for empty", in: "${EMPTY:+set}", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandValue(tt.in, vars); got != tt.want {
+				t.Errorf("expandValue(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadEnvInterpolation(t *testing.T) {
+	filename, err := createTempEnvFile(`INTERP_BASE=http://localhost
+INTERP_API=${INTERP_BASE}/api
+INTERP_HEALTH=$INTERP_API/health
+INTERP_LITERAL='${INTERP_BASE}'
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	if err := LoadEnv(filename); err != nil {
+		t.Fatalf("LoadEnv() error = %v", err)
+	}
+
+	want := map[string]string{
+		"INTERP_API":     "http://localhost/api",
+		"INTERP_HEALTH":  "http://localhost/api/health",
+		"INTERP_LITERAL": "${INTERP_BASE}",
+	}
+	for key, val := range want {
+		if got := os.Getenv(key); got != val {
+			t.Errorf("%s = %q, want %q", key, got, val)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	filename, err := createTempEnvFile("EXPAND_PUB_HOST=localhost\nEXPAND_PUB_PORT=5432\n")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	vars, err := GetAll(filename)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		vars map[string]string
+		want string
+	}{
+		{name: "file variables", in: "${EXPAND_PUB_HOST}:$EXPAND_PUB_PORT", vars: vars, want: "localhost:5432"},
+		{name: "default", in: "${EXPAND_PUB_USER:-admin}", vars: vars, want: "admin"},
+		{name: "nil vars", in: "${EXPAND_PUB_HOST}", vars: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Expand(tt.in, tt.vars); got != tt.want {
+				t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
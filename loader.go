@@ -0,0 +1,267 @@
+package env
+
+import (
+	"io"
+	"strings"
+)
+
+// Loader reads .env files using a reusable set of parsing rules. It offers a
+// single configuration surface in place of choosing between the LoadEnv
+// variants:
+//
+//	loader := &env.Loader{Override: true, Expand: true, Strict: true}
+//	if err := loader.Load(".env"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The zero value neither overrides existing variables nor expands references.
+// LoadEnv and Parse behave like a Loader with Override and Expand set, and
+// GetEnv like one with only Override set. For lookups that expand references,
+// use the Get, Lookup, and Parse methods of a Loader with Expand set.
+type Loader struct {
+	// Override lets file values replace variables already present in the
+	// process environment. When false, existing variables win: they are not
+	// set again and references to them expand to the existing value.
+	Override bool
+
+	// Expand enables interpolation in values that are not single-quoted,
+	// using the reference syntax selected by ExpandSyntax.
+	Expand bool
+
+	// ExpandSyntax selects which reference forms Expand recognizes. The zero
+	// value, ExpandDollar, recognizes ${VAR} and $VAR.
+	ExpandSyntax ExpandSyntax
+
+	// Strict makes malformed lines and unterminated quoted values an error
+	// instead of silently skipping them.
+	Strict bool
+
+	// ValidateKeys checks keys with IsValidKey. Invalid keys are an error in
+	// strict mode and skipped otherwise.
+	ValidateKeys bool
+
+	// FileRefs treats keys ending in _FILE as references to a file whose
+	// trimmed contents become the value of the key without the suffix, as
+	// described for LoadEnvFileRefs.
+	FileRefs bool
+
+	// RejectTrailingSpace makes trailing spaces or tabs after an unquoted
+	// value an error instead of trimming them, to catch typos such as
+	// "DB_HOST=localhost ". Whitespace before an inline comment is allowed.
+	RejectTrailingSpace bool
+
+	// KeyCase normalizes the case of every key as soon as it is parsed, before
+	// validation, expansion bookkeeping, and os.Setenv. Lookups and references
+	// to earlier keys must then use the normalized form.
+	KeyCase KeyCase
+
+	// Append lets a line written as KEY+=value add to the current value of
+	// KEY instead of replacing it. The current value is the last one defined
+	// earlier in the same file or, failing that, the one in the process
+	// environment, which lets a later file extend a list loaded from an
+	// earlier one. Values are joined with AppendSeparator. Appending updates
+	// an existing variable, so with Override false it does not apply to keys
+	// already present in the process environment.
+	Append bool
+
+	// AppendSeparator joins appended values. An empty AppendSeparator
+	// means ":".
+	AppendSeparator string
+
+	// Separator splits keys from values at its first occurrence on a line.
+	// An empty Separator means "=".
+	Separator string
+
+	// CommentPrefix marks full-line and inline comments. An empty
+	// CommentPrefix means "#".
+	CommentPrefix string
+
+	// LineContinuation joins an unquoted value ending in a backslash with the
+	// following line, as in a shell script: the backslash is removed and the
+	// next line, without its leading whitespace, is appended. A value ending
+	// in an escaped backslash (\\) is not continued. An inline comment may
+	// follow the value on its last line; as in a shell, a backslash inside an
+	// inline comment does not continue the line. Quoted values are never
+	// continued.
+	LineContinuation bool
+
+	// SkipEmpty leaves keys whose parsed value is empty untouched instead of
+	// setting them to an empty string, so FOO= in the file means "leave FOO
+	// unchanged". It affects only Load, LoadReader, and DryRun; Parse and
+	// Lookup still report empty values, and later lines may still reference
+	// them.
+	SkipEmpty bool
+
+	// MaxLineSize limits the length in bytes of a single line, which bounds
+	// the memory used for each line. Longer lines, such as large base64
+	// values, make parsing fail with an error naming the line. A zero
+	// MaxLineSize means bufio.MaxScanTokenSize (64 KiB).
+	MaxLineSize int
+
+	// keepComments makes parseEntries report full-line comments as entries,
+	// for ParseReaderOrderedComments.
+	keepComments bool
+
+	// keepExisting makes parseEntries report a key that is not overridden
+	// because it is already set in the process environment, with the value
+	// the process keeps, for Lookup.
+	keepExisting bool
+}
+
+// KeyCase selects how a Loader normalizes key names.
+type KeyCase int
+
+// Key case normalizations supported by Loader.
+const (
+	// KeyCaseNone leaves keys as written in the file.
+	KeyCaseNone KeyCase = iota
+	// KeyCaseUpper converts keys to upper case.
+	KeyCaseUpper
+	// KeyCaseLower converts keys to lower case.
+	KeyCaseLower
+)
+
+// apply returns key normalized according to c.
+func (c KeyCase) apply(key string) string {
+	switch c {
+	case KeyCaseUpper:
+		return strings.ToUpper(key)
+	case KeyCaseLower:
+		return strings.ToLower(key)
+	}
+	return key
+}
+
+// ExpandSyntax selects the variable reference forms a Loader expands.
+type ExpandSyntax int
+
+// Reference syntaxes supported by Loader.
+const (
+	// ExpandDollar recognizes the shell forms ${VAR} and $VAR, including
+	// ${VAR:-default} and ${VAR:+alt}, with $$ producing a literal $.
+	ExpandDollar ExpandSyntax = iota
+	// ExpandPercent recognizes the Windows form %VAR%, with %% producing a
+	// literal %. A % that does not start a reference to a valid key, as in
+	// "50% off", is kept literally.
+	ExpandPercent
+	// ExpandDollarPercent recognizes both forms. Neither takes precedence:
+	// references are resolved in a single left-to-right pass, the form that
+	// starts first is expanded, and the text it expands to is not scanned
+	// again. A % inside ${...}, as in ${PORT:-50%}, is part of the reference.
+	ExpandDollarPercent
+)
+
+// expand replaces the references recognized by x in s.
+func (x ExpandSyntax) expand(s string, vars map[string]string) string {
+	return expandRefs(s, vars, x != ExpandPercent, x != ExpandDollar)
+}
+
+// defaultLoader holds the rules used by LoadEnv, Parse, and Walk.
+var defaultLoader = &Loader{Override: true, Expand: true}
+
+// lookupLoader holds the rules used by GetEnv and its variants, which return
+// values unexpanded.
+var lookupLoader = &Loader{Override: true}
+
+// Load reads environment variables from a file and sets them in the process
+// environment according to the loader's rules.
+//
+// Returns an error if the file cannot be opened or read, or if parsing fails
+// under the loader's rules.
+func (l *Loader) Load(filename string) error {
+	return l.parseFile(filename, l.setenv)
+}
+
+// LoadReader reads environment variables from r and sets them in the process
+// environment according to the loader's rules.
+//
+// Returns an error if r cannot be read, or if parsing fails under the loader's
+// rules.
+func (l *Loader) LoadReader(r io.Reader) error {
+	return l.parse(r, l.setenv)
+}
+
+// Parse reads the given file according to the loader's rules and returns its
+// key/value pairs without modifying the process environment. When a key
+// appears more than once the last value wins. If Override is false, keys
+// already present in the process environment are left out.
+//
+// Returns an error if the file cannot be opened or read, or if parsing fails
+// under the loader's rules.
+func (l *Loader) Parse(filename string) (map[string]string, error) {
+	vars := make(map[string]string)
+	err := l.parseFile(filename, func(key, value string, _ int) error {
+		vars[key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// DryRun returns exactly the key/value pairs Load would set for the given
+// file without modifying the process environment. It differs from Parse only
+// in applying SkipEmpty, so empty values Load would skip are left out and do
+// not replace an earlier value of the same key.
+//
+// Returns an error if the file cannot be opened or read, or if parsing fails
+// under the loader's rules.
+func (l *Loader) DryRun(filename string) (map[string]string, error) {
+	vars := make(map[string]string)
+	err := l.parseFile(filename, func(key, value string, _ int) error {
+		if l.SkipEmpty && value == "" {
+			return nil
+		}
+		vars[key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// Get returns the first value for key in the given file according to the
+// loader's rules, or an empty string if the key is not present. With Expand
+// set it is the expanding counterpart of GetEnv: references resolve against
+// earlier keys in the file and then the process environment, exactly as Load
+// would set the key.
+//
+//	loader := &env.Loader{Expand: true}
+//	url, err := loader.Get("API_URL", ".env")
+//
+// Returns an error if the file cannot be opened or read, or if parsing fails
+// before the key is found.
+func (l *Loader) Get(key, filename string) (string, error) {
+	value, _, err := l.Lookup(key, filename)
+	return value, err
+}
+
+// Lookup returns the first value for key in the given file according to the
+// loader's rules and reports whether the key was present.
+//
+// Without Override, a key the file defines that is already set in the
+// process environment returns the environment's value, which is the value
+// Load would leave in place.
+//
+// Returns an error if the file cannot be opened or read, or if parsing fails
+// before the key is found.
+func (l *Loader) Lookup(key, filename string) (string, bool, error) {
+	lookup := *l
+	lookup.keepExisting = true
+
+	var value string
+	var found bool
+	err := lookup.parseFile(filename, func(k, v string, _ int) error {
+		if k != key {
+			return nil
+		}
+		value, found = v, true
+		return errStop
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return value, found, nil
+}
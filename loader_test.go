@@ -0,0 +1,507 @@
+package env
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoader(t *testing.T) {
+	filename, err := createTempEnvFile(`; legacy settings
+LOADER_HOST: localhost
+LOADER_URL: http://${LOADER_HOST}:8080 ; inline
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	loader := &Loader{Override: true, Expand: true, Separator: ":", CommentPrefix: ";"}
+
+	vars, err := loader.Parse(filename)
+	if err != nil {
+		t.Fatalf("Loader.Parse() error = %v", err)
+	}
+	if got := vars["LOADER_URL"]; got != "http://localhost:8080" {
+		t.Errorf("Loader.Parse()[LOADER_URL] = %q, want http://localhost:8080", got)
+	}
+	if _, ok := os.LookupEnv("LOADER_HOST"); ok {
+		t.Error("Loader.Parse() must not modify the process environment")
+	}
+
+	if err := loader.Load(filename); err != nil {
+		t.Fatalf("Loader.Load() error = %v", err)
+	}
+	if got := os.Getenv("LOADER_HOST"); got != "localhost" {
+		t.Errorf("LOADER_HOST = %q, want localhost", got)
+	}
+
+	value, found, err := loader.Lookup("LOADER_URL", filename)
+	if err != nil || !found || value != "http://localhost:8080" {
+		t.Errorf("Loader.Lookup() = %q, %v, %v, want http://localhost:8080, true, nil", value, found, err)
+	}
+}
+
+func TestLoaderZeroValue(t *testing.T) {
+	filename, err := createTempEnvFile(`ZERO_HOST=file-host
+ZERO_URL=${ZERO_HOST}/api
+INVALID_LINE
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	os.Setenv("ZERO_HOST", "env-host")
+	defer os.Unsetenv("ZERO_HOST")
+
+	var loader Loader
+	if err := loader.Load(filename); err != nil {
+		t.Fatalf("Loader.Load() error = %v", err)
+	}
+	if got := os.Getenv("ZERO_HOST"); got != "env-host" {
+		t.Errorf("ZERO_HOST = %q, want env-host", got)
+	}
+	if got := os.Getenv("ZERO_URL"); got != "${ZERO_HOST}/api" {
+		t.Errorf("ZERO_URL = %q, want unexpanded reference", got)
+	}
+
+	strict := &Loader{Strict: true}
+	if err := strict.Load(filename); err == nil {
+		t.Error("Loader.Load() expected error for malformed line in strict mode")
+	}
+}
+
+func TestLoaderGet(t *testing.T) {
+	os.Setenv("LOADER_GET_OS", "from-os")
+	defer os.Unsetenv("LOADER_GET_OS")
+	filename, err := createTempEnvFile(`LOADER_GET_HOST=localhost
+LOADER_GET_URL=http://${LOADER_GET_HOST}/$LOADER_GET_OS
+LOADER_GET_LITERAL='${LOADER_GET_HOST}'
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name   string
+		loader *Loader
+		key    string
+		want   string
+	}{
+		{name: "unexpanded", loader: &Loader{Override: true}, key: "LOADER_GET_URL", want: "http://${LOADER_GET_HOST}/$LOADER_GET_OS"},
+		{name: "expanded", loader: &Loader{Override: true, Expand: true}, key: "LOADER_GET_URL", want: "http://localhost/from-os"},
+		{name: "single-quoted", loader: &Loader{Override: true, Expand: true}, key: "LOADER_GET_LITERAL", want: "${LOADER_GET_HOST}"},
+		{name: "missing", loader: &Loader{Override: true, Expand: true}, key: "LOADER_GET_MISSING", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.loader.Get(tt.key, filename)
+			if err != nil {
+				t.Fatalf("Loader.Get() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Loader.Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	got, err := GetEnv("LOADER_GET_URL", filename)
+	if err != nil {
+		t.Fatalf("GetEnv() error = %v", err)
+	}
+	if want := "http://${LOADER_GET_HOST}/$LOADER_GET_OS"; got != want {
+		t.Errorf("GetEnv() = %q, want %q", got, want)
+	}
+}
+
+func TestLoaderLookupKeepsEnvironment(t *testing.T) {
+	os.Setenv("LOADER_LOOKUP_SET", "from-os")
+	defer os.Unsetenv("LOADER_LOOKUP_SET")
+	filename, err := createTempEnvFile("LOADER_LOOKUP_SET=from-file\nLOADER_LOOKUP_NEW=new\n")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name   string
+		loader *Loader
+		key    string
+		want   string
+	}{
+		{name: "kept", loader: &Loader{}, key: "LOADER_LOOKUP_SET", want: "from-os"},
+		{name: "overridden", loader: &Loader{Override: true}, key: "LOADER_LOOKUP_SET", want: "from-file"},
+		{name: "unset", loader: &Loader{}, key: "LOADER_LOOKUP_NEW", want: "new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := tt.loader.Lookup(tt.key, filename)
+			if err != nil {
+				t.Fatalf("Loader.Lookup() error = %v", err)
+			}
+			if !found || got != tt.want {
+				t.Errorf("Loader.Lookup() = %q, %v, want %q, true", got, found, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoaderRejectTrailingSpace(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "clean value", content: "TRAIL_HOST=localhost\n"},
+		{name: "inline comment", content: "TRAIL_HOST=localhost # comment\n"},
+		{name: "quoted value", content: "TRAIL_HOST=\"localhost \"  \n"},
+		{name: "trailing space", content: "TRAIL_OK=1\nTRAIL_HOST=localhost \n", wantErr: "line 2: trailing whitespace in value for TRAIL_HOST"},
+		{name: "trailing tab", content: "TRAIL_HOST=localhost\t\n", wantErr: "line 1: trailing whitespace in value for TRAIL_HOST"},
+	}
+
+	loader := &Loader{RejectTrailingSpace: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename, err := createTempEnvFile(tt.content)
+			if err != nil {
+				t.Fatalf("failed to create temp file: %v", err)
+			}
+			defer os.Remove(filename)
+
+			_, err = loader.Parse(filename)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Loader.Parse() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Loader.Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoaderKeyCase(t *testing.T) {
+	filename, err := createTempEnvFile(`Case_Host=localhost
+case_url=http://${CASE_HOST}
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name    string
+		keyCase KeyCase
+		want    map[string]string
+	}{
+		{name: "none", keyCase: KeyCaseNone, want: map[string]string{"Case_Host": "localhost", "case_url": "http://"}},
+		{name: "upper", keyCase: KeyCaseUpper, want: map[string]string{"CASE_HOST": "localhost", "CASE_URL": "http://localhost"}},
+		{name: "lower", keyCase: KeyCaseLower, want: map[string]string{"case_host": "localhost", "case_url": "http://"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := &Loader{Override: true, Expand: true, KeyCase: tt.keyCase}
+			got, err := loader.Parse(filename)
+			if err != nil {
+				t.Fatalf("Loader.Parse() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("Loader.Parse() = %v, want %v", got, tt.want)
+			}
+			for key, val := range tt.want {
+				if got[key] != val {
+					t.Errorf("Loader.Parse()[%s] = %q, want %q", key, got[key], val)
+				}
+			}
+		})
+	}
+}
+
+func TestLoaderExpandSyntax(t *testing.T) {
+	filename, err := createTempEnvFile(`SYNTAX_HOST=localhost
+SYNTAX_DOLLAR=http://${SYNTAX_HOST}
+SYNTAX_PERCENT=http://%SYNTAX_HOST%
+SYNTAX_LITERAL=50% off, 100%% sure
+SYNTAX_MIXED=%SYNTAX_HOST%:$SYNTAX_HOST:${SYNTAX_MISSING:-5%}
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name   string
+		syntax ExpandSyntax
+		want   map[string]string
+	}{
+		{
+			name:   "dollar",
+			syntax: ExpandDollar,
+			want: map[string]string{
+				"SYNTAX_DOLLAR":  "http://localhost",
+				"SYNTAX_PERCENT": "http://%SYNTAX_HOST%",
+				"SYNTAX_LITERAL": "50% off, 100%% sure",
+				"SYNTAX_MIXED":   "%SYNTAX_HOST%:localhost:5%",
+			},
+		},
+		{
+			name:   "percent",
+			syntax: ExpandPercent,
+			want: map[string]string{
+				"SYNTAX_DOLLAR":  "http://${SYNTAX_HOST}",
+				"SYNTAX_PERCENT": "http://localhost",
+				"SYNTAX_LITERAL": "50% off, 100% sure",
+				"SYNTAX_MIXED":   "localhost:$SYNTAX_HOST:${SYNTAX_MISSING:-5%}",
+			},
+		},
+		{
+			name:   "both",
+			syntax: ExpandDollarPercent,
+			want: map[string]string{
+				"SYNTAX_DOLLAR":  "http://localhost",
+				"SYNTAX_PERCENT": "http://localhost",
+				"SYNTAX_LITERAL": "50% off, 100% sure",
+				"SYNTAX_MIXED":   "localhost:localhost:5%",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := &Loader{Override: true, Expand: true, ExpandSyntax: tt.syntax}
+			got, err := loader.Parse(filename)
+			if err != nil {
+				t.Fatalf("Loader.Parse() error = %v", err)
+			}
+			for key, val := range tt.want {
+				if got[key] != val {
+					t.Errorf("Loader.Parse()[%s] = %q, want %q", key, got[key], val)
+				}
+			}
+		})
+	}
+}
+
+func TestLoaderAppend(t *testing.T) {
+	base, err := createTempEnvFile(`APPEND_PATHS=/usr/bin
+APPEND_PATHS+=/usr/local/bin
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(base)
+
+	local, err := createTempEnvFile(`APPEND_PATHS += /opt/bin
+APPEND_NEW+=first
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(local)
+
+	defer os.Unsetenv("APPEND_PATHS")
+	defer os.Unsetenv("APPEND_NEW")
+
+	loader := &Loader{Override: true, Expand: true, Append: true}
+	for _, filename := range []string{base, local} {
+		if err := loader.Load(filename); err != nil {
+			t.Fatalf("Loader.Load() error = %v", err)
+		}
+	}
+	if got, want := os.Getenv("APPEND_PATHS"), "/usr/bin:/usr/local/bin:/opt/bin"; got != want {
+		t.Errorf("APPEND_PATHS = %q, want %q", got, want)
+	}
+	if got := os.Getenv("APPEND_NEW"); got != "first" {
+		t.Errorf("APPEND_NEW = %q, want first", got)
+	}
+
+	comma := &Loader{Override: true, Append: true, AppendSeparator: ","}
+	vars, err := comma.Parse(base)
+	if err != nil {
+		t.Fatalf("Loader.Parse() error = %v", err)
+	}
+	if got, want := vars["APPEND_PATHS"], "/usr/bin,/usr/local/bin"; got != want {
+		t.Errorf("Loader.Parse()[APPEND_PATHS] = %q, want %q", got, want)
+	}
+
+	plain, err := (&Loader{Override: true}).Parse(base)
+	if err != nil {
+		t.Fatalf("Loader.Parse() error = %v", err)
+	}
+	if got := plain["APPEND_PATHS+"]; got != "/usr/local/bin" {
+		t.Errorf("Loader.Parse()[APPEND_PATHS+] = %q, want /usr/local/bin without Append", got)
+	}
+}
+
+func TestLoaderMaxLineSize(t *testing.T) {
+	long := strings.Repeat("a", bufio.MaxScanTokenSize+10)
+	filename, err := createTempEnvFile("MAXLINE_SHORT=ok\nMAXLINE_LONG=" + long + "\n")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	_, err = (&Loader{}).Parse(filename)
+	if !errors.Is(err, bufio.ErrTooLong) || !strings.Contains(err.Error(), "line 2:") {
+		t.Errorf("Parse() error = %v, want line 2 error wrapping %v", err, bufio.ErrTooLong)
+	}
+
+	vars, err := (&Loader{MaxLineSize: 2 * bufio.MaxScanTokenSize}).Parse(filename)
+	if err != nil {
+		t.Fatalf("Parse() with MaxLineSize error = %v", err)
+	}
+	if vars["MAXLINE_LONG"] != long {
+		t.Errorf("Parse()[MAXLINE_LONG] has length %d, want %d", len(vars["MAXLINE_LONG"]), len(long))
+	}
+
+	line := "MAXLINE_EXACT=" + strings.Repeat("b", 16)
+	if err := (&Loader{MaxLineSize: len(line)}).LoadReader(strings.NewReader(line + "\n")); err != nil {
+		t.Errorf("LoadReader() with line of exactly MaxLineSize error = %v", err)
+	}
+	if err := (&Loader{MaxLineSize: len(line)}).LoadReader(strings.NewReader(line + "\r\n")); err != nil {
+		t.Errorf("LoadReader() with CRLF line of exactly MaxLineSize error = %v", err)
+	}
+	err = (&Loader{MaxLineSize: len(line) - 1}).LoadReader(strings.NewReader(line + "\r\n"))
+	if !errors.Is(err, bufio.ErrTooLong) || !strings.Contains(err.Error(), "line 1:") {
+		t.Errorf("LoadReader() with CRLF line over MaxLineSize error = %v, want line 1 error wrapping %v", err, bufio.ErrTooLong)
+	}
+}
+
+func TestLoaderShebang(t *testing.T) {
+	tests := []struct {
+		name    string
+		loader  *Loader
+		content string
+		wantErr bool
+	}{
+		{name: "default comment", loader: &Loader{Strict: true}, content: "#!/usr/bin/env bash\nSHEBANG_A=1\n"},
+		{name: "custom comment", loader: &Loader{Strict: true, CommentPrefix: ";"}, content: "#!/usr/bin/env bash\nSHEBANG_A=1\n"},
+		{name: "after byte-order mark", loader: &Loader{Strict: true, CommentPrefix: ";"}, content: "\ufeff#!/bin/sh\nSHEBANG_A=1\n"},
+		{name: "not on first line", loader: &Loader{Strict: true, CommentPrefix: ";"}, content: "SHEBANG_A=1\n#!/bin/sh\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename, err := createTempEnvFile(tt.content)
+			if err != nil {
+				t.Fatalf("failed to create temp file: %v", err)
+			}
+			defer os.Remove(filename)
+
+			vars, err := tt.loader.Parse(filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (len(vars) != 1 || vars["SHEBANG_A"] != "1") {
+				t.Errorf("Parse() = %v, want map[SHEBANG_A:1]", vars)
+			}
+		})
+	}
+}
+
+func TestLoaderSkipEmpty(t *testing.T) {
+	os.Setenv("SKIP_EMPTY_KEEP", "existing")
+	os.Unsetenv("SKIP_EMPTY_UNSET")
+	defer os.Unsetenv("SKIP_EMPTY_KEEP")
+	defer os.Unsetenv("SKIP_EMPTY_SET")
+	content := "SKIP_EMPTY_KEEP=\nSKIP_EMPTY_UNSET=''\nSKIP_EMPTY_SET=value\n"
+
+	if err := (&Loader{Override: true, SkipEmpty: true}).LoadReader(strings.NewReader(content)); err != nil {
+		t.Fatalf("LoadReader() error = %v", err)
+	}
+	if got := os.Getenv("SKIP_EMPTY_KEEP"); got != "existing" {
+		t.Errorf("SKIP_EMPTY_KEEP = %q, want existing", got)
+	}
+	if _, ok := os.LookupEnv("SKIP_EMPTY_UNSET"); ok {
+		t.Error("SKIP_EMPTY_UNSET was set, want unset")
+	}
+	if got := os.Getenv("SKIP_EMPTY_SET"); got != "value" {
+		t.Errorf("SKIP_EMPTY_SET = %q, want value", got)
+	}
+
+	if err := (&Loader{Override: true}).LoadReader(strings.NewReader(content)); err != nil {
+		t.Fatalf("LoadReader() error = %v", err)
+	}
+	defer os.Unsetenv("SKIP_EMPTY_UNSET")
+	if got, ok := os.LookupEnv("SKIP_EMPTY_KEEP"); !ok || got != "" {
+		t.Errorf("SKIP_EMPTY_KEEP = %q, %v, want empty and set without SkipEmpty", got, ok)
+	}
+}
+
+func TestLoaderLineContinuation(t *testing.T) {
+	content := `CONT_LIST=a,\
+    b,\
+    c
+CONT_COMMENT=one \
+two # note
+CONT_IN_COMMENT=x # not continued \
+CONT_ESCAPED=C:\\
+CONT_QUOTED='q\'
+CONT_LAST=end\`
+
+	tests := []struct {
+		name   string
+		loader *Loader
+		want   map[string]string
+	}{
+		{
+			name:   "enabled",
+			loader: &Loader{Override: true, LineContinuation: true},
+			want: map[string]string{
+				"CONT_LIST":       "a,b,c",
+				"CONT_COMMENT":    "one two",
+				"CONT_IN_COMMENT": "x",
+				"CONT_ESCAPED":    `C:\\`,
+				"CONT_QUOTED":     `q\`,
+				"CONT_LAST":       `end\`,
+			},
+		},
+		{
+			name:   "disabled",
+			loader: &Loader{Override: true},
+			want: map[string]string{
+				"CONT_LIST":       `a,\`,
+				"CONT_COMMENT":    `one \`,
+				"CONT_IN_COMMENT": "x",
+				"CONT_ESCAPED":    `C:\\`,
+				"CONT_QUOTED":     `q\`,
+				"CONT_LAST":       `end\`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]string)
+			err := tt.loader.parse(strings.NewReader(content), func(key, value string, _ int) error {
+				got[key] = value
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var lines []int
+	err := (&Loader{LineContinuation: true}).parse(strings.NewReader("A=1\\\n2\nB=3\n"), func(_, _ string, line int) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(lines, []int{1, 3}) {
+		t.Errorf("parse() lines = %v, %v, want [1 3], nil", lines, err)
+	}
+}
@@ -0,0 +1,317 @@
+package env
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Marshal serializes vars into .env file content with one KEY=VALUE line per
+// entry. Keys are sorted so the output is deterministic. Values containing
+// whitespace, comment markers, quotes, newlines, or "$" are quoted so that
+// parsing the result with Parse yields the original values:
+//
+//   - values without single quotes or line breaks are wrapped in single
+//     quotes, which are never expanded
+//   - other values are wrapped in double quotes with "$" written as "$$",
+//     backslashes and double quotes escaped, and newlines and carriage
+//     returns written as \n and \r
+//
+// Returns an error if a key is empty or contains "=", whitespace, or "#".
+func Marshal(vars map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		if err := validateMarshalKey(key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(quoteValue(vars[key]))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalOrdered serializes pairs into .env file content in the given order,
+// which together with ParseOrderedRaw lets tools edit a file with minimal
+// diffs. Values other than single-quoted ones are taken as raw text, as
+// returned by ParseOrderedRaw, so ${VAR} and $VAR references are written as
+// references rather than escaped. Each value keeps the quoting style recorded
+// in its Quote field when that style can represent it: unquoted values stay
+// unquoted unless they need quotes, single-quoted values stay single-quoted
+// unless they contain a single quote or line break, and double-quoted values
+// stay double-quoted. Unquoted values that need quotes are single-quoted when
+// they hold no reference and double-quoted otherwise. Comment entries, as
+// returned by ParseReaderOrderedComments, are written as their own line.
+//
+// Returns an error if a key is empty or contains "=", whitespace, or "#".
+func MarshalOrdered(pairs []KeyValue) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, p := range pairs {
+		if p.Key == "" && p.Comment != "" {
+			buf.WriteString(p.Comment)
+			buf.WriteByte('\n')
+			continue
+		}
+		if err := validateMarshalKey(p.Key); err != nil {
+			return nil, err
+		}
+		buf.WriteString(p.Key)
+		buf.WriteByte('=')
+		buf.WriteString(quoteRawAs(p.Value, p.Quote))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// validateMarshalKey reports an error for keys that would not parse back to
+// themselves.
+func validateMarshalKey(key string) error {
+	if key == "" {
+		return errors.New("invalid empty key")
+	}
+	if strings.ContainsAny(key, "= \t\r\n#") {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	return nil
+}
+
+// quoteValue returns value in a form that parses back to itself.
+func quoteValue(value string) string {
+	if !needsQuoting(value) {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\r\n") {
+		return "'" + value + "'"
+	}
+	return `"` + doubleQuoteEscaper.Replace(value) + `"`
+}
+
+// quoteValueAs returns value in a form that parses back to itself, using the
+// quote character q when it can represent value.
+func quoteValueAs(value string, q byte) string {
+	switch {
+	case q == '"':
+		return `"` + doubleQuoteEscaper.Replace(value) + `"`
+	case q == '\'' && !strings.ContainsAny(value, "'\r\n"):
+		return "'" + value + "'"
+	}
+	return quoteValue(value)
+}
+
+// quoteRawAs returns value, which holds raw text with any references left
+// unexpanded, in a form that parses back to the same raw text, using the quote
+// character q when it can represent value. Single-quoted values are literal,
+// so they are quoted as for quoteValueAs.
+func quoteRawAs(value string, q byte) string {
+	switch {
+	case q == '\'':
+		return quoteValueAs(value, q)
+	case q == 0 && !needsQuoting(strings.ReplaceAll(value, "$", "")):
+		return value
+	case q == 0 && !strings.ContainsAny(value, "'\r\n$"):
+		return "'" + value + "'"
+	}
+	return `"` + rawQuoteEscaper.Replace(value) + `"`
+}
+
+// rawQuoteEscaper escapes the characters that are special inside a
+// double-quoted value and writes line breaks as escape sequences, leaving $
+// references intact.
+var rawQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+
+// doubleQuoteEscaper escapes the characters that are special inside a
+// double-quoted value and writes line breaks as escape sequences, so a
+// carriage return survives the line-ending normalization of the parser.
+var doubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", "$$", "\n", `\n`, "\r", `\r`)
+
+// needsQuoting reports whether value must be quoted to survive parsing.
+func needsQuoting(value string) bool {
+	return strings.ContainsAny(value, " \t\r\n#'\"$")
+}
+
+// Write serializes vars with Marshal and saves the result to filename. The
+// content is written to a temporary file in the same directory which is then
+// renamed over filename, so readers never observe a partially written file.
+// The file is created with 0600 permissions since .env files often hold
+// secrets.
+//
+// Returns an error if vars cannot be marshaled or the file cannot be written.
+func Write(filename string, vars map[string]string) error {
+	data, err := Marshal(vars)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, data, 0600)
+}
+
+// SaveEnvPrefix writes every process environment variable whose name starts
+// with prefix to filename in .env format, replacing any existing file. It is
+// useful for snapshotting configuration changed at runtime. Values are quoted
+// as described for Marshal, and the file is written atomically with 0600
+// permissions like Write. An empty prefix saves the whole environment.
+//
+// Returns an error if a matching variable cannot be marshaled or the file
+// cannot be written.
+func SaveEnvPrefix(filename, prefix string) error {
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(key, prefix) {
+			vars[key] = value
+		}
+	}
+	return Write(filename, vars)
+}
+
+// writeFileAtomic writes data to a temporary file next to filename and
+// renames it into place.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, filename)
+}
+
+// SetEnvFile sets key to value in the given file without disturbing unrelated
+// lines. If the key is already defined, the line holding its last definition
+// (the one LoadEnv would apply) is replaced, including every line of a
+// multiline value; otherwise a new line is appended. Only the value text of a
+// replaced line changes, so its indentation and inline comment are kept, and
+// an appended line uses the line ending of the file's first line. Comments,
+// blank lines, and the order of other keys are preserved, and the value is
+// quoted as by Marshal when needed.
+//
+// The file is replaced atomically and keeps its permissions. If it does not
+// exist it is created with 0600 permissions.
+//
+// Returns an error if the key is invalid or the file cannot be read or written.
+func SetEnvFile(filename, key, value string) error {
+	if err := validateMarshalKey(key); err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0600)
+	data, err := os.ReadFile(filename)
+	switch {
+	case err == nil:
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		perm = info.Mode().Perm()
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	start := 0
+	err = lookupLoader.parse(bytes.NewReader(data), func(k, _ string, line int) error {
+		if k == key {
+			start = line
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	entry := key + "=" + quoteValue(value)
+	if start == 0 {
+		eol := "\n"
+		if i := bytes.IndexByte(data, '\n'); i > 0 && data[i-1] == '\r' {
+			eol = "\r\n"
+		}
+		if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+			data = append(data, eol...)
+		}
+		data = append(data, entry+eol...)
+		return writeFileAtomic(filename, data, perm)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	first, last := start-1, valueEnd(lines, start-1)
+	if line, ok := spliceValue(strings.Join(lines[first:last+1], "\n"), quoteValue(value)); ok {
+		entry = line
+	} else {
+		if first == 0 && strings.HasPrefix(lines[0], utf8BOM) {
+			entry = utf8BOM + entry
+		}
+		if strings.HasSuffix(lines[last], "\r") {
+			entry += "\r"
+		}
+	}
+	updated := append(append(lines[:first:first], entry), lines[last+1:]...)
+	return writeFileAtomic(filename, []byte(strings.Join(updated, "\n")), perm)
+}
+
+// spliceValue replaces the value in raw, the text of a KEY=VALUE definition
+// with its lines joined by "\n", with quoted. The text before the value, such
+// as indentation and the key, is kept, as are whitespace and an inline comment
+// after it and a trailing carriage return. It reports false if raw has no
+// separator.
+func spliceValue(raw, quoted string) (string, bool) {
+	body := strings.TrimSuffix(raw, "\r")
+	sep := strings.Index(body, "=")
+	if sep < 0 {
+		return "", false
+	}
+	value := strings.TrimLeft(body[sep+1:], " \t")
+	prefix := body[:len(body)-len(value)]
+	suffix := value[len(strings.TrimRight(stripInlineComment(value, "#"), " \t")):]
+	if suffix != "" && suffix[0] != ' ' && suffix[0] != '\t' {
+		suffix = " " + suffix
+	}
+	return prefix + quoted + suffix + raw[len(body):], true
+}
+
+// valueEnd returns the index of the last line of the definition starting at
+// lines[i], following a double-quoted value across lines until it closes. An
+// unterminated quote covers only its own line, as when parsing.
+func valueEnd(lines []string, i int) int {
+	parts := strings.SplitN(lines[i], "=", 2)
+	if len(parts) != 2 {
+		return i
+	}
+	if v := strings.TrimSpace(parts[1]); !strings.HasPrefix(v, `"`) || closesQuote(v) {
+		return i
+	}
+	for j := i + 1; j < len(lines); j++ {
+		if closingQuote(`"`+lines[j]) >= 0 {
+			if closesMultiline(lines[j], "#") {
+				return j
+			}
+			return i
+		}
+	}
+	return i
+}
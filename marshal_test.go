@@ -0,0 +1,374 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	vars := map[string]string{
+		"PORT":     "5432",
+		"APP_NAME": "My Application",
+		"PASSWORD": "a#b",
+		"PRICE":    "$5",
+		"KEY":      "line1\nline2",
+		"CRLF":     "a\r\nb",
+		"QUOTE":    "it's",
+		"MIXED":    `it's "quoted" \ ok`,
+		"EMPTY":    "",
+	}
+
+	got, err := Marshal(vars)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	want := `APP_NAME='My Application'
+CRLF="a\r\nb"
+EMPTY=
+KEY="line1\nline2"
+MIXED="it's \"quoted\" \\ ok"
+PASSWORD='a#b'
+PORT=5432
+PRICE='$5'
+QUOTE="it's"
+`
+	if string(got) != want {
+		t.Errorf("Marshal() = %q, want %q", got, want)
+	}
+
+	filename, err := createTempEnvFile(string(got))
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	parsed, err := Parse(filename)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for key, val := range vars {
+		if parsed[key] != val {
+			t.Errorf("round trip %s = %q, want %q", key, parsed[key], val)
+		}
+	}
+
+	if _, err := Marshal(map[string]string{"BAD KEY": "x"}); err == nil {
+		t.Error("Marshal() expected error for invalid key")
+	}
+}
+
+func TestMarshalOrdered(t *testing.T) {
+	source := `PORT=5432
+NAME='My App'
+GREETING="hello world"
+QUOTED_NUMBER="8080"
+EMPTY=
+PORT=6543
+BASE=http://h
+URL="${BASE}/x"
+HOME_DIR=$HOME
+PRICE='$5'
+`
+	filename, err := createTempEnvFile(source)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	pairs, err := ParseOrderedRaw(filename)
+	if err != nil {
+		t.Fatalf("ParseOrderedRaw() error = %v", err)
+	}
+	got, err := MarshalOrdered(pairs)
+	if err != nil {
+		t.Fatalf("MarshalOrdered() error = %v", err)
+	}
+	if string(got) != source {
+		t.Errorf("MarshalOrdered() = %q, want %q", got, source)
+	}
+
+	tests := []struct {
+		name string
+		pair KeyValue
+		want string
+	}{
+		{name: "unquoted needing quotes", pair: KeyValue{Key: "A", Value: "a b"}, want: "A='a b'\n"},
+		{name: "single-quoted with single quote", pair: KeyValue{Key: "A", Value: "it's", Quote: '\''}, want: `A="it's"` + "\n"},
+		{name: "double-quoted with escapes", pair: KeyValue{Key: "A", Value: `say "hi" \ ok`, Quote: '"'}, want: `A="say \"hi\" \\ ok"` + "\n"},
+		{name: "double-quoted reference", pair: KeyValue{Key: "A", Value: `${BASE} "v2"`, Quote: '"'}, want: `A="${BASE} \"v2\""` + "\n"},
+		{name: "unquoted reference needing quotes", pair: KeyValue{Key: "A", Value: "${BASE} v2"}, want: `A="${BASE} v2"` + "\n"},
+		{name: "single-quoted dollar falling back", pair: KeyValue{Key: "A", Value: "it's $5", Quote: '\''}, want: `A="it's $$5"` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalOrdered([]KeyValue{tt.pair})
+			if err != nil {
+				t.Fatalf("MarshalOrdered() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalOrdered() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := MarshalOrdered([]KeyValue{{Key: "BAD KEY", Value: "x"}}); err == nil {
+		t.Error("MarshalOrdered() expected error for invalid key")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	vars := map[string]string{
+		"DB_HOST":  "localhost",
+		"APP_NAME": "My Application",
+	}
+
+	if err := Write(filename, vars); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Write() permissions = %v, want 0600", perm)
+	}
+
+	parsed, err := Parse(filename)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for key, val := range vars {
+		if parsed[key] != val {
+			t.Errorf("Parse()[%s] = %q, want %q", key, parsed[key], val)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Write() left %d files in directory, want 1", len(entries))
+	}
+
+	if err := Write(filepath.Join(t.TempDir(), "missing", ".env"), vars); err == nil {
+		t.Error("Write() expected error for missing directory")
+	}
+}
+
+func TestSaveEnvPrefix(t *testing.T) {
+	os.Setenv("SAVE_PFX_HOST", "localhost")
+	os.Setenv("SAVE_PFX_NAME", "My \"App\" #1 $HOME")
+	os.Setenv("SAVE_OTHER", "excluded")
+	defer func() {
+		os.Unsetenv("SAVE_PFX_HOST")
+		os.Unsetenv("SAVE_PFX_NAME")
+		os.Unsetenv("SAVE_OTHER")
+	}()
+
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := SaveEnvPrefix(filename, "SAVE_PFX_"); err != nil {
+		t.Fatalf("SaveEnvPrefix() error = %v", err)
+	}
+
+	parsed, err := Parse(filename)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := map[string]string{
+		"SAVE_PFX_HOST": "localhost",
+		"SAVE_PFX_NAME": "My \"App\" #1 $HOME",
+	}
+	if !reflect.DeepEqual(parsed, want) {
+		t.Errorf("Parse() after SaveEnvPrefix() = %v, want %v", parsed, want)
+	}
+
+	if err := SaveEnvPrefix(filepath.Join(t.TempDir(), "missing", ".env"), "SAVE_PFX_"); err == nil {
+		t.Error("SaveEnvPrefix() expected error for missing directory")
+	}
+}
+
+func TestSetEnvFile(t *testing.T) {
+	content := `# App settings
+APP_VERSION=1.0.0
+APP_KEY="-----BEGIN-----
+abc
+-----END-----"
+
+# Database
+DB_HOST=localhost # local
+APP_VERSION=1.0.1
+`
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{
+			name:  "update last definition",
+			key:   "APP_VERSION",
+			value: "2.0.0",
+			want: `# App settings
+APP_VERSION=1.0.0
+APP_KEY="-----BEGIN-----
+abc
+-----END-----"
+
+# Database
+DB_HOST=localhost # local
+APP_VERSION=2.0.0
+`,
+		},
+		{
+			name:  "replace multiline value",
+			key:   "APP_KEY",
+			value: "short key",
+			want: `# App settings
+APP_VERSION=1.0.0
+APP_KEY='short key'
+
+# Database
+DB_HOST=localhost # local
+APP_VERSION=1.0.1
+`,
+		},
+		{
+			name:  "append new key",
+			key:   "LOG_LEVEL",
+			value: "debug",
+			want:  content + "LOG_LEVEL=debug\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(filename, []byte(content), 0640); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+
+			if err := SetEnvFile(filename, tt.key, tt.value); err != nil {
+				t.Fatalf("SetEnvFile() error = %v", err)
+			}
+
+			got, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("SetEnvFile() content = %q, want %q", got, tt.want)
+			}
+
+			info, err := os.Stat(filename)
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if perm := info.Mode().Perm(); perm != 0640 {
+				t.Errorf("SetEnvFile() permissions = %v, want 0640", perm)
+			}
+		})
+	}
+}
+
+func TestSetEnvFileKeepsLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		key    string
+		value  string
+		want   string
+	}{
+		{
+			name:   "indentation and comment",
+			source: "  PORT=5432 # default port\n",
+			key:    "PORT",
+			value:  "6000",
+			want:   "  PORT=6000 # default port\n",
+		},
+		{
+			name:   "quoted value in CRLF file",
+			source: "\ufeffNAME=\"old\" # display\r\n",
+			key:    "NAME",
+			value:  "new name",
+			want:   "\ufeffNAME='new name' # display\r\n",
+		},
+		{
+			name:   "append to CRLF file",
+			source: "A=1\r\n",
+			key:    "B",
+			value:  "2",
+			want:   "A=1\r\nB=2\r\n",
+		},
+		{
+			name:   "append to CRLF file without final newline",
+			source: "A=1\r\nC=3",
+			key:    "B",
+			value:  "2",
+			want:   "A=1\r\nC=3\r\nB=2\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(filename, []byte(tt.source), 0600); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			if err := SetEnvFile(filename, tt.key, tt.value); err != nil {
+				t.Fatalf("SetEnvFile() error = %v", err)
+			}
+			got, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("SetEnvFile() content = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetEnvFileCreate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := SetEnvFile(filename, "NEW_KEY", "value"); err != nil {
+		t.Fatalf("SetEnvFile() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != "NEW_KEY=value\n" {
+		t.Errorf("SetEnvFile() content = %q, want %q", got, "NEW_KEY=value\n")
+	}
+
+	if err := SetEnvFile(filename, "BAD KEY", "value"); err == nil {
+		t.Error("SetEnvFile() expected error for invalid key")
+	}
+}
+
+func TestSetEnvFileCRLF(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(filename, []byte("# comment\r\nAPP_VERSION=1.0.0\r\nDB_HOST=localhost\r\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := SetEnvFile(filename, "APP_VERSION", "2.0.0"); err != nil {
+		t.Fatalf("SetEnvFile() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := "# comment\r\nAPP_VERSION=2.0.0\r\nDB_HOST=localhost\r\n"
+	if string(got) != want {
+		t.Errorf("SetEnvFile() content = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,678 @@
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// utf8BOM is the byte-order mark some editors write at the start of a file.
+const utf8BOM = "\ufeff"
+
+// shebang starts an interpreter line, which is ignored on the first line so
+// a file can be both sourced as a script and loaded as configuration.
+const shebang = "#!"
+
+// fileRefSuffix marks keys whose value names a file to read when file
+// references are enabled.
+const fileRefSuffix = "_FILE"
+
+// appendMarker ends a key whose value is appended to rather than replaced
+// when appending is enabled, as in PATHS+=/opt/bin.
+const appendMarker = "+"
+
+// defaultAppendSeparator joins appended values when no separator is set.
+const defaultAppendSeparator = ":"
+
+// errStop is returned by parse callbacks to end parsing early without
+// reporting an error.
+var errStop = errors.New("stop parsing")
+
+// parse scans r line by line and calls fn for every KEY=VALUE pair in file
+// order, along with the 1-based line number where the pair starts. A UTF-8
+// byte-order mark at the start of the input is ignored, as are a shebang line
+// such as "#!/usr/bin/env bash" on the first line and the carriage return of
+// CRLF line endings. Leading spaces and tabs are trimmed from every
+// line, so indented comments and pairs are recognized. Comments, empty lines,
+// and malformed lines are skipped.
+//
+// Values have surrounding quotes removed and, when expansion is enabled and
+// the value is not single-quoted, references to earlier keys or the process
+// environment expanded. Whitespace around keys, unquoted values, and the
+// quotes of quoted values is trimmed, while whitespace inside quotes is kept.
+// Double-quoted values interpret the escape sequences \n, \t, \r, \\, and \";
+// single-quoted values are kept literally. A double-quoted value that is not
+// closed on its own line continues across the following lines until a line
+// whose first quote is followed by nothing but whitespace or an inline
+// comment, keeping the embedded newlines. If the input ends first, or the
+// next quote is followed by other text as in B="def", the value is
+// unterminated: it is taken from its own line alone and the lines after it
+// are parsed as usual, so a stray quote cannot swallow later pairs. An unquoted comment marker
+// preceded by whitespace starts an inline comment that runs to the end of the
+// line; a marker inside quotes or directly after other text, as in
+// KEY=value#x, is part of the value. A line whose only separator follows such
+// a comment, as in "KEY value # a=b", has no separator and is malformed.
+//
+// A null byte anywhere in the input stops parsing with an error naming its
+// line, since it indicates a binary file rather than a text env file. In
+// strict mode a malformed line also stops parsing with an error naming its
+// 1-based line number. Otherwise parsing stops only at the first error
+// returned by fn; errStop ends parsing without an error.
+func (l *Loader) parse(r io.Reader, fn func(key, value string, line int) error) error {
+	return l.parseEntries(r, func(e entry) error {
+		if e.comment {
+			return nil
+		}
+		return fn(e.key, e.value, e.line)
+	})
+}
+
+// entry is a key/value pair produced by parseEntries together with the
+// details of how it was written.
+type entry struct {
+	key   string
+	value string
+	// line is the 1-based line number where the pair starts.
+	line int
+	// quote is the quote character surrounding the value in the file, or 0
+	// if it was unquoted or read from a referenced file.
+	quote byte
+	// comment marks a full-line comment, whose text including the marker
+	// is held in value. Comments are only reported when the loader's
+	// keepComments is set.
+	comment bool
+}
+
+// parseEntries implements parse, passing each pair to fn as an entry.
+func (l *Loader) parseEntries(r io.Reader, fn func(e entry) error) error {
+	vars := make(map[string]string)
+	comment := l.CommentPrefix
+	if comment == "" {
+		comment = "#"
+	}
+
+	maxLine := l.MaxLineSize
+	if maxLine <= 0 {
+		maxLine = bufio.MaxScanTokenSize
+	}
+	scanner := &lineScanner{Scanner: bufio.NewScanner(r), max: maxLine}
+	// The buffer holds the longest allowed line with its CR and LF; the
+	// scanner itself enforces maxLine once the CR is stripped.
+	scanner.Buffer(nil, maxLine+2)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if err := checkNullByte(line, lineNum); err != nil {
+			return err
+		}
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, utf8BOM)
+		}
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, comment) || lineNum == 1 && strings.HasPrefix(line, shebang) {
+			if l.keepComments {
+				if err := fn(entry{value: line, line: lineNum, comment: true}); err != nil {
+					return ignoreStop(err)
+				}
+			}
+			continue
+		}
+
+		sep := l.Separator
+		if sep == "" {
+			sep = "="
+		}
+		parts := strings.SplitN(line, sep, 2)
+		var key string
+		if len(parts) == 2 && stripInlineComment(parts[0], comment) == parts[0] {
+			key = l.KeyCase.apply(unquoteKey(strings.TrimSpace(parts[0])))
+		}
+		if key == "" {
+			if l.Strict {
+				return &ParseError{Line: lineNum, Content: line, Err: fmt.Errorf("malformed line %q", line)}
+			}
+			continue
+		}
+
+		appending := false
+		if l.Append && strings.HasSuffix(key, appendMarker) {
+			key = strings.TrimSpace(strings.TrimSuffix(key, appendMarker))
+			appending = true
+		}
+
+		if l.ValidateKeys && !IsValidKey(key) {
+			if l.Strict {
+				return &ParseError{Line: lineNum, Content: line, Err: fmt.Errorf("invalid key %q", key)}
+			}
+			continue
+		}
+
+		start := lineNum
+		value := parts[1]
+		if v := strings.TrimSpace(value); strings.HasPrefix(v, `"`) && !closesQuote(v) {
+			lines, closed, err := readMultiline(scanner, &lineNum, comment)
+			if err != nil {
+				return err
+			}
+			if err := scanner.Err(); err != nil {
+				return scanError(err, lineNum+1, maxLine)
+			}
+			if !closed && l.Strict {
+				return &ParseError{Line: start, Content: line, Err: fmt.Errorf("unterminated quoted value for %s", key)}
+			}
+			if closed {
+				value = strings.TrimSpace(v + "\n" + strings.Join(lines, "\n"))
+			} else {
+				// Keep a single-line value so a stray quote does not swallow
+				// later lines; they are parsed again as usual.
+				scanner.unread(lines)
+				lineNum = start
+			}
+		} else if l.LineContinuation && !strings.HasPrefix(v, `"`) && !strings.HasPrefix(v, "'") {
+			for continues(value, comment) && scanner.Scan() {
+				lineNum++
+				next := strings.TrimSuffix(scanner.Text(), "\r")
+				if err := checkNullByte(next, lineNum); err != nil {
+					return err
+				}
+				value = value[:len(value)-1] + strings.TrimLeft(next, " \t")
+			}
+			if err := scanner.Err(); err != nil {
+				return scanError(err, lineNum+1, maxLine)
+			}
+		}
+		stripped := stripInlineComment(value, comment)
+		hadComment := len(stripped) != len(value)
+		value, quote := unquote(strings.TrimSpace(stripped))
+		if l.RejectTrailingSpace && quote == 0 && !hadComment && hasTrailingSpace(stripped) {
+			return &ParseError{Line: start, Content: line, Err: fmt.Errorf("trailing whitespace in value for %s", key)}
+		}
+		if l.Expand && quote != '\'' {
+			value = l.ExpandSyntax.expand(value, vars)
+		}
+
+		if l.FileRefs && len(key) > len(fileRefSuffix) && strings.HasSuffix(key, fileRefSuffix) {
+			data, err := os.ReadFile(value)
+			if err != nil {
+				return &ParseError{Line: start, Content: line, Err: fmt.Errorf("reading %s for %s: %w", value, key, err)}
+			}
+			key = strings.TrimSuffix(key, fileRefSuffix)
+			value = strings.TrimSpace(string(data))
+			quote = 0
+		}
+
+		if appending {
+			value = l.appendValue(key, value, vars)
+		}
+
+		if !l.Override {
+			if existing, ok := os.LookupEnv(key); ok {
+				vars[key] = existing
+				if l.keepExisting {
+					if err := fn(entry{key: key, value: existing, line: start}); err != nil {
+						return ignoreStop(err)
+					}
+				}
+				continue
+			}
+		}
+
+		vars[key] = value
+		if err := fn(entry{key: key, value: value, line: start, quote: quote}); err != nil {
+			return ignoreStop(err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return scanError(err, lineNum+1, maxLine)
+	}
+	return nil
+}
+
+// ParseError describes a problem with a specific line of env input, such as
+// a malformed line in strict mode. Use errors.As to recover the line number:
+//
+//	var perr *env.ParseError
+//	if errors.As(err, &perr) {
+//		fmt.Println("bad line", perr.Line)
+//	}
+type ParseError struct {
+	// Line is the 1-based line number where the problem was found. For a
+	// value spanning several lines it is the line where the pair starts.
+	Line int
+	// Content is the text of the offending line, or empty if the line could
+	// not be read.
+	Content string
+	// Err is the underlying problem.
+	Err error
+}
+
+// Error formats the error as "line N: " followed by the underlying error.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ignoreStop returns err unless it is errStop, which ends parsing without
+// an error.
+func ignoreStop(err error) error {
+	if err == errStop {
+		return nil
+	}
+	return err
+}
+
+// scanError describes a scanner failure at lineNum. Lines exceeding the
+// maximum line size get an error naming the limit instead of the bare
+// bufio.ErrTooLong.
+func scanError(err error, lineNum, maxLine int) error {
+	if errors.Is(err, bufio.ErrTooLong) {
+		return &ParseError{Line: lineNum, Err: fmt.Errorf("line exceeds maximum size of %d bytes; raise Loader.MaxLineSize to allow it: %w", maxLine, err)}
+	}
+	return err
+}
+
+// appendValue joins value onto the current value of key, taken from earlier
+// lines or else the process environment, using the loader's append separator.
+// When key has no current value, value is returned unchanged.
+func (l *Loader) appendValue(key, value string, vars map[string]string) string {
+	existing, ok := vars[key]
+	if !ok {
+		existing = os.Getenv(key)
+	}
+	if existing == "" {
+		return value
+	}
+
+	sep := l.AppendSeparator
+	if sep == "" {
+		sep = defaultAppendSeparator
+	}
+	return existing + sep + value
+}
+
+// setenv is a parse callback that sets each pair in the process
+// environment, or records it in record mode. Empty values are skipped when
+// SkipEmpty is set.
+func (l *Loader) setenv(key, value string, _ int) error {
+	if l.SkipEmpty && value == "" {
+		return nil
+	}
+	return setVar(key, value)
+}
+
+// stdinFilename is the filename that selects standard input.
+const stdinFilename = "-"
+
+// parseFile opens filename and parses it with parse. The filename "-" reads
+// from standard input instead.
+func (l *Loader) parseFile(filename string, fn func(key, value string, line int) error) error {
+	return l.parseFileEntries(filename, func(e entry) error {
+		return fn(e.key, e.value, e.line)
+	})
+}
+
+// parseFileEntries opens filename with openFile and parses it with
+// parseEntries, reading standard input for the filename "-".
+func (l *Loader) parseFileEntries(filename string, fn func(e entry) error) error {
+	file, err := openInput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return l.parseEntries(file, fn)
+}
+
+// openInput opens filename like openFile, except that stdinFilename selects
+// standard input, which is left open when the result is closed.
+func openInput(filename string) (io.ReadCloser, error) {
+	if filename == stdinFilename {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return openFile(filename)
+}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// openFile opens filename for reading. A directory is reported with a clear
+// error instead of the read error it would otherwise produce. Gzip-compressed
+// files, recognized by their magic bytes rather than their name, are
+// decompressed transparently.
+func openFile(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := file.Stat(); err == nil && info.IsDir() {
+		file.Close()
+		return nil, fmt.Errorf("%s is a directory, not an env file", filename)
+	}
+
+	br := bufio.NewReader(file)
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		return &gzipFile{Reader: zr, file: file}, nil
+	}
+	return &bufferedFile{Reader: br, file: file}, nil
+}
+
+// bufferedFile reads a file through the buffer used to detect compression.
+type bufferedFile struct {
+	*bufio.Reader
+	file *os.File
+}
+
+func (f *bufferedFile) Close() error {
+	return f.file.Close()
+}
+
+// gzipFile decompresses a gzip-compressed file as it is read.
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (f *gzipFile) Close() error {
+	err := f.Reader.Close()
+	if cerr := f.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// contextReader fails reads with the context's error once it is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// unquoteKey removes a matching pair of single or double quotes surrounding
+// key, so "DB HOST"=localhost defines the key DB HOST.
+func unquoteKey(key string) string {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+// continues reports whether an unquoted value ends with a backslash that
+// continues it on the next line. An escaped backslash, written as \\, does
+// not, and neither does a backslash inside an inline comment.
+func continues(value, comment string) bool {
+	if stripInlineComment(value, comment) != value {
+		return false
+	}
+	n := len(value) - len(strings.TrimRight(value, `\`))
+	return n%2 == 1
+}
+
+// hasTrailingSpace reports whether s ends with a space or tab.
+func hasTrailingSpace(s string) bool {
+	return strings.HasSuffix(s, " ") || strings.HasSuffix(s, "\t")
+}
+
+// closesQuote reports whether a value starting with a double quote also
+// contains its closing quote.
+func closesQuote(value string) bool {
+	return closingQuote(value) >= 0
+}
+
+// closingQuote returns the index of the quote that closes the one at
+// value[0], or -1 if there is none. Inside double quotes a backslash escapes
+// the following character.
+func closingQuote(value string) int {
+	q := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case q == '"' && value[i] == '\\':
+			i++
+		case value[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote removes the quotes surrounding value and returns the quote
+// character used, or 0 for unquoted values. Unquoted values are returned
+// verbatim, including any quote or separator characters they contain. Escape
+// sequences in double-quoted values are interpreted. A value whose opening
+// quote is closed before its end, as in "a,b",c, is not a quoted value and is
+// returned verbatim. Values that open a quote without a matching close have
+// any leading and trailing quote characters trimmed.
+func unquote(value string) (string, byte) {
+	if value == "" {
+		return value, 0
+	}
+
+	q := value[0]
+	if q != '"' && q != '\'' {
+		return value, 0
+	}
+	end := -1
+	if len(value) > 1 {
+		end = closingQuote(value)
+	}
+	switch {
+	case end == len(value)-1:
+		inner := value[1 : len(value)-1]
+		if q == '"' {
+			inner = unescape(inner)
+		}
+		return inner, q
+	case end > 0:
+		return value, 0
+	}
+	return strings.Trim(value, `"'`), q
+}
+
+// unescape interprets the escape sequences recognized in double-quoted values:
+// \n, \t, \r, \\, and \". Other backslashes are kept as-is.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\', '"':
+			b.WriteByte(s[i+1])
+		default:
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+	}
+	return b.String()
+}
+
+// stripInlineComment removes a trailing inline comment starting with marker
+// from value. For quoted values only text after the closing quote is
+// considered, so a marker inside the quotes is preserved. For unquoted values,
+// and for unquoted text following a closing quote as in "a,b",c, the marker
+// starts a comment only when it is preceded by a space or tab.
+func stripInlineComment(value, marker string) string {
+	trimmed := strings.TrimLeft(value, " \t")
+	if trimmed == "" {
+		return value
+	}
+
+	if q := trimmed[0]; q == '"' || q == '\'' {
+		end := closingQuote(trimmed)
+		if end < 0 {
+			return value
+		}
+		end++
+		rest := trimmed[end:]
+		if strings.HasPrefix(strings.TrimSpace(rest), marker) {
+			return trimmed[:end]
+		}
+		if i := commentIndex(rest, marker); i >= 0 {
+			return trimmed[:end+i]
+		}
+		return value
+	}
+
+	if i := commentIndex(value, marker); i >= 0 {
+		return value[:i]
+	}
+	return value
+}
+
+// commentIndex returns the index of the first marker in s that is preceded by
+// a space or tab, or -1 if there is none.
+func commentIndex(s, marker string) int {
+	for i := 1; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], marker) && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// readMultiline reads the lines following the opening line of a
+// double-quoted value from scanner until a line contains a quote, and returns
+// them without their carriage returns. It advances lineNum for every line
+// consumed and reports whether that quote closes the value, as decided by
+// closesMultiline; a quote followed by other text, or the end of the input,
+// leaves the value unterminated. Lines are checked for null bytes like any
+// other.
+func readMultiline(scanner *lineScanner, lineNum *int, marker string) ([]string, bool, error) {
+	var lines []string
+	for scanner.Scan() {
+		*lineNum++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if err := checkNullByte(line, *lineNum); err != nil {
+			return nil, false, err
+		}
+		lines = append(lines, line)
+		if closingQuote(`"`+line) >= 0 {
+			return lines, closesMultiline(line, marker), nil
+		}
+	}
+	return lines, false, nil
+}
+
+// closesMultiline reports whether the first unescaped quote in line, a line
+// inside a multiline double-quoted value, closes the value: only whitespace
+// or an inline comment starting with marker may follow it. A line such as
+// B="def" holds the opening quote of another value instead.
+func closesMultiline(line, marker string) bool {
+	end := closingQuote(`"` + line)
+	if end < 0 {
+		return false
+	}
+	rest := strings.TrimSpace(line[end:])
+	return rest == "" || strings.HasPrefix(rest, marker)
+}
+
+// lineScanner is a bufio.Scanner that can push lines back to be scanned
+// again, which parseEntries uses to recover from an unterminated quote.
+// Lines longer than max, not counting a trailing CR, stop scanning with
+// bufio.ErrTooLong.
+type lineScanner struct {
+	*bufio.Scanner
+	max     int
+	pending []string
+	text    string
+	err     error
+}
+
+// Scan advances to the next pushed-back line or, once there are none, the
+// next line of the underlying scanner.
+func (s *lineScanner) Scan() bool {
+	if len(s.pending) > 0 {
+		s.text, s.pending = s.pending[0], s.pending[1:]
+		return true
+	}
+	if s.err != nil || !s.Scanner.Scan() {
+		return false
+	}
+	s.text = s.Scanner.Text()
+	if len(strings.TrimSuffix(s.text, "\r")) > s.max {
+		s.err = bufio.ErrTooLong
+		return false
+	}
+	return true
+}
+
+// Err returns the first error met while scanning, including a line that
+// exceeds max.
+func (s *lineScanner) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.Scanner.Err()
+}
+
+// Text returns the line read by the last call to Scan.
+func (s *lineScanner) Text() string {
+	return s.text
+}
+
+// unread pushes lines back so that Scan returns them again, in order, before
+// reading further input.
+func (s *lineScanner) unread(lines []string) {
+	s.pending = append(append([]string(nil), lines...), s.pending...)
+}
+
+// checkNullByte reports an error if line contains a null byte, which means
+// the input is most likely a binary file rather than a text env file.
+func checkNullByte(line string, lineNum int) error {
+	if strings.IndexByte(line, 0) >= 0 {
+		return &ParseError{Line: lineNum, Content: line, Err: errors.New("null byte found; input is not a text env file")}
+	}
+	return nil
+}
+
+// IsValidKey reports whether key is a valid environment variable name under
+// the POSIX grammar [A-Za-z_][A-Za-z0-9_]*.
+func IsValidKey(key string) bool {
+	if key == "" || !isNameStart(key[0]) {
+		return false
+	}
+	for i := 1; i < len(key); i++ {
+		if !isNameChar(key[i]) {
+			return false
+		}
+	}
+	return true
+}
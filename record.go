@@ -0,0 +1,73 @@
+package env
+
+import (
+	"os"
+	"sync"
+)
+
+// recorder holds the state of record mode, see SetRecordMode.
+var recorder struct {
+	mu      sync.Mutex
+	enabled bool
+	vars    map[string]string
+}
+
+// SetRecordMode switches record mode on or off. While record mode is on,
+// LoadEnv and every other function that sets variables store them in an
+// internal map, available through Snapshot, instead of calling os.Setenv, and
+// UnsetEnv removes them from that map. This lets tests assert on loaded
+// configuration without touching the process environment:
+//
+//	env.SetRecordMode(true)
+//	defer env.SetRecordMode(false)
+//	env.LoadEnv("testdata/.env")
+//	vars := env.Snapshot()
+//
+// Each call clears the recorded variables. Record mode is off by default. It
+// affects only where variables are written; references and the Override
+// check still consult the process environment.
+func SetRecordMode(enabled bool) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.enabled = enabled
+	recorder.vars = nil
+	if enabled {
+		recorder.vars = make(map[string]string)
+	}
+}
+
+// Snapshot returns a copy of the variables recorded since record mode was
+// switched on. It returns an empty map when record mode is off.
+func Snapshot() map[string]string {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	vars := make(map[string]string, len(recorder.vars))
+	for key, value := range recorder.vars {
+		vars[key] = value
+	}
+	return vars
+}
+
+// setVar sets key to value in the process environment or, in record mode, in
+// the recorded variables.
+func setVar(key, value string) error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.enabled {
+		recorder.vars[key] = value
+		return nil
+	}
+	return os.Setenv(key, value)
+}
+
+// unsetVar removes key from the process environment or, in record mode, from
+// the recorded variables.
+func unsetVar(key string) error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.enabled {
+		delete(recorder.vars, key)
+		return nil
+	}
+	return os.Unsetenv(key)
+}
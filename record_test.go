@@ -0,0 +1,54 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSetRecordMode(t *testing.T) {
+	filename, err := createTempEnvFile(`RECORD_HOST=localhost
+RECORD_URL=http://${RECORD_HOST}
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	SetRecordMode(true)
+	defer SetRecordMode(false)
+
+	if err := LoadEnv(filename); err != nil {
+		t.Fatalf("LoadEnv() error = %v", err)
+	}
+	want := map[string]string{
+		"RECORD_HOST": "localhost",
+		"RECORD_URL":  "http://localhost",
+	}
+	if got := Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() = %v, want %v", got, want)
+	}
+	if _, ok := os.LookupEnv("RECORD_HOST"); ok {
+		t.Error("LoadEnv() in record mode modified the process environment")
+	}
+
+	if err := UnsetEnv(filename); err != nil {
+		t.Fatalf("UnsetEnv() error = %v", err)
+	}
+	if got := Snapshot(); len(got) != 0 {
+		t.Errorf("Snapshot() after UnsetEnv() = %v, want empty", got)
+	}
+
+	SetRecordMode(false)
+	if err := LoadEnv(filename); err != nil {
+		t.Fatalf("LoadEnv() error = %v", err)
+	}
+	defer os.Unsetenv("RECORD_HOST")
+	defer os.Unsetenv("RECORD_URL")
+	if got := os.Getenv("RECORD_HOST"); got != "localhost" {
+		t.Errorf("RECORD_HOST = %v, want localhost", got)
+	}
+	if got := Snapshot(); len(got) != 0 {
+		t.Errorf("Snapshot() with record mode off = %v, want empty", got)
+	}
+}
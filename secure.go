@@ -0,0 +1,47 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// ErrInsecurePermissions is returned by LoadEnvSecure when a file can be read
+// by users other than its owner.
+var ErrInsecurePermissions = errors.New("file is readable by group or others")
+
+// LoadEnvSecure reads environment variables from a file like LoadEnv, but
+// first refuses files whose permissions grant read access to the group or to
+// others, such as 0644. Since .env files often hold secrets, this enforces
+// 0600-style permissions in production.
+//
+// The check is skipped when reading from standard input and on Windows, where
+// Unix permission bits are not meaningful. It cannot be disabled through the
+// environment, which an earlier env file could set; where permissions cannot
+// be tightened, such as on some mounted volumes, call LoadEnv instead.
+//
+// Returns an error wrapping ErrInsecurePermissions if the check fails, or an
+// error if the file cannot be stat'ed, opened, or read.
+func LoadEnvSecure(filename string) error {
+	if err := checkPermissions(filename); err != nil {
+		return err
+	}
+	return LoadEnv(filename)
+}
+
+// checkPermissions implements the permission check of LoadEnvSecure.
+func checkPermissions(filename string) error {
+	if filename == stdinFilename || runtime.GOOS == "windows" {
+		return nil
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	if perm := info.Mode().Perm(); perm&0044 != 0 {
+		return fmt.Errorf("%s: %w (mode %04o)", filename, ErrInsecurePermissions, perm)
+	}
+	return nil
+}
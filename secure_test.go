@@ -0,0 +1,42 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEnvSecure(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(filename, []byte("SECURE_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	defer os.Unsetenv("SECURE_KEY")
+	if err := LoadEnvSecure(filename); err != nil {
+		t.Fatalf("LoadEnvSecure() error = %v", err)
+	}
+	if got := os.Getenv("SECURE_KEY"); got != "secret" {
+		t.Errorf("SECURE_KEY = %v, want secret", got)
+	}
+
+	for _, perm := range []os.FileMode{0644, 0640, 0604} {
+		if err := os.Chmod(filename, perm); err != nil {
+			t.Fatalf("failed to chmod file: %v", err)
+		}
+		if err := LoadEnvSecure(filename); !errors.Is(err, ErrInsecurePermissions) {
+			t.Errorf("LoadEnvSecure() with mode %04o error = %v, want %v", perm, err, ErrInsecurePermissions)
+		}
+	}
+
+	os.Setenv("GOENV_ALLOW_INSECURE", "1")
+	defer os.Unsetenv("GOENV_ALLOW_INSECURE")
+	if err := LoadEnvSecure(filename); !errors.Is(err, ErrInsecurePermissions) {
+		t.Errorf("LoadEnvSecure() with GOENV_ALLOW_INSECURE set error = %v, want %v", err, ErrInsecurePermissions)
+	}
+
+	if err := LoadEnvSecure("non_existent_file.env"); err == nil {
+		t.Error("LoadEnvSecure() expected error for non-existent file")
+	}
+}
@@ -0,0 +1,426 @@
+package env
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by the typed getters when the requested key is not
+// present in the file. Use errors.Is to detect it and apply a default.
+var ErrNotFound = errors.New("key not found")
+
+// lookupRequired returns the value of key from the given file, or an error
+// wrapping ErrNotFound if the key is missing.
+func lookupRequired(key, filename string) (string, error) {
+	value, found, err := LookupEnv(key, filename)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return value, nil
+}
+
+// GetEnvInt retrieves the value of key from the given file and parses it as a
+// base-10 integer.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key and value if it is not a valid integer, or an error if the file
+// cannot be opened or read.
+func GetEnvInt(key, filename string) (int, error) {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("key %s: cannot parse %q as int", key, value)
+	}
+	return n, nil
+}
+
+// GetEnvIntRange retrieves the value of key from the given file like
+// GetEnvInt and checks that it lies within the inclusive range [min, max],
+// which catches misconfigured ports or pool sizes at startup.
+//
+// Returns the errors of GetEnvInt, or an error naming the key, the value, and
+// the allowed range if the value is out of range.
+func GetEnvIntRange(key, filename string, min, max int) (int, error) {
+	n, err := GetEnvInt(key, filename)
+	if err != nil {
+		return 0, err
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("key %s: %d is outside the allowed range [%d, %d]", key, n, min, max)
+	}
+	return n, nil
+}
+
+// GetEnvBool retrieves the value of key from the given file and parses it as a
+// boolean. The forms true/false, 1/0, yes/no, and on/off are recognized
+// case-insensitively.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key and value if it is not a recognized boolean, or an error if the file
+// cannot be opened or read.
+func GetEnvBool(key, filename string) (bool, error) {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := parseBool(value)
+	if !ok {
+		return false, fmt.Errorf("key %s: cannot parse %q as bool", key, value)
+	}
+	return b, nil
+}
+
+// parseBool interprets the boolean forms accepted by GetEnvBool.
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "true", "1", "yes", "on":
+		return true, true
+	case "false", "0", "no", "off":
+		return false, true
+	}
+	return false, false
+}
+
+// GetEnvDuration retrieves the value of key from the given file and parses it
+// with time.ParseDuration, so values such as "30s" or "1h15m" are accepted.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key and value if it is not a valid duration, or an error if the file
+// cannot be opened or read.
+func GetEnvDuration(key, filename string) (time.Duration, error) {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return 0, err
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("key %s: cannot parse %q as duration", key, value)
+	}
+	return d, nil
+}
+
+// GetEnvTime retrieves the value of key from the given file and parses it as
+// an RFC 3339 timestamp such as "2024-01-01T00:00:00Z". Use GetEnvTimeLayout
+// for other formats.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key, the value, and the expected format if it does not parse, or an
+// error if the file cannot be opened or read.
+func GetEnvTime(key, filename string) (time.Time, error) {
+	return GetEnvTimeLayout(key, filename, time.RFC3339)
+}
+
+// GetEnvTimeLayout behaves like GetEnvTime but parses the value with
+// time.Parse using layout, such as time.DateOnly or "2006-01-02 15:04".
+func GetEnvTimeLayout(key, filename, layout string) (time.Time, error) {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("key %s: cannot parse %q as time in format %s", key, value, layout)
+	}
+	return t, nil
+}
+
+// GetEnvFloat retrieves the value of key from the given file and parses it as
+// a 64-bit floating point number.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key and value if it is not a valid number, or an error if the file
+// cannot be opened or read.
+func GetEnvFloat(key, filename string) (float64, error) {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("key %s: cannot parse %q as float64", key, value)
+	}
+	return f, nil
+}
+
+// GetEnvBytes retrieves the value of key from the given file and parses it as
+// a human-readable size in bytes, such as "256MB" or "1.5 GiB". The decimal
+// units B, KB, MB, GB, and TB are powers of 1000 and the binary units KiB,
+// MiB, GiB, and TiB powers of 1024; units are matched case-insensitively and
+// a bare number is a count of bytes. Fractional results are truncated.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key and value if the number or unit is not recognized or the size
+// overflows an int64, or an error if the file cannot be opened or read.
+func GetEnvBytes(key, filename string) (int64, error) {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return 0, err
+	}
+
+	n, ok := parseBytes(value)
+	if !ok {
+		return 0, fmt.Errorf("key %s: cannot parse %q as byte size", key, value)
+	}
+	return n, nil
+}
+
+// byteUnits maps the lower-cased units accepted by GetEnvBytes to their size.
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseBytes interprets the sizes accepted by GetEnvBytes.
+func parseBytes(value string) (int64, bool) {
+	value = strings.TrimSpace(value)
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(value)
+	}
+	num, unit := value[:i], strings.TrimSpace(value[i:])
+
+	mult, ok := byteUnits[strings.ToLower(unit)]
+	if !ok || num == "" {
+		return 0, false
+	}
+	if n, err := strconv.ParseInt(num, 10, 64); err == nil {
+		if n > math.MaxInt64/mult {
+			return 0, false
+		}
+		return n * mult, true
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f*float64(mult) >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f * float64(mult)), true
+}
+
+// GetEnvSlice retrieves the value of key from the given file and splits it on
+// sep, trimming surrounding whitespace from each element. A missing key or an
+// empty value yields an empty slice rather than a slice holding one empty
+// string.
+//
+// Returns an error if sep is empty or the file cannot be opened or read.
+func GetEnvSlice(key, filename, sep string) ([]string, error) {
+	if sep == "" {
+		return nil, errors.New("separator must not be empty")
+	}
+
+	value, err := GetEnv(key, filename)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(value) == "" {
+		return []string{}, nil
+	}
+
+	parts := strings.Split(value, sep)
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts, nil
+}
+
+// GetEnvCSV retrieves the value of key from the given file and splits it as a
+// single comma-separated record with encoding/csv, so elements may be quoted
+// to contain commas: NAMES="a,b",c yields "a,b" and "c". A doubled quote
+// inside a quoted element stands for one quote. Leading whitespace before
+// each element is ignored. Like GetEnvSlice, a missing key or an empty value
+// yields an empty slice.
+//
+// Returns an error naming the key if the value is not a single valid CSV
+// record, or an error if the file cannot be opened or read.
+func GetEnvCSV(key, filename string) ([]string, error) {
+	value, err := GetEnv(key, filename)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(value) == "" {
+		return []string{}, nil
+	}
+
+	r := csv.NewReader(strings.NewReader(value))
+	r.TrimLeadingSpace = true
+	fields, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("key %s: malformed CSV: %w", key, err)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		if err == nil {
+			err = errors.New("more than one record")
+		}
+		return nil, fmt.Errorf("key %s: malformed CSV: %w", key, err)
+	}
+	return fields, nil
+}
+
+// GetEnvJSON retrieves the value of key from the given file and decodes it as
+// JSON into v, which must be a pointer as for json.Unmarshal.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key and wrapping the json error if decoding fails, or an error if the
+// file cannot be opened or read.
+func GetEnvJSON(key, filename string, v interface{}) error {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return fmt.Errorf("key %s: %w", key, err)
+	}
+	return nil
+}
+
+// GetEnvURL retrieves the value of key from the given file and parses it as
+// a URL with net/url. The URL must be absolute, with both a scheme and a host,
+// so values such as "api.example.com" or "localhost:8080" are rejected.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key and value if it is not a valid absolute URL, or an error if the file
+// cannot be opened or read.
+func GetEnvURL(key, filename string) (*url.URL, error) {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", key, err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("key %s: %q is not an absolute URL", key, value)
+	}
+	return u, nil
+}
+
+// GetEnvEnum retrieves the value of key from the given file and checks that
+// it is one of allowed, which catches typos in settings such as LOG_LEVEL.
+// Matching is case-sensitive; use GetEnvEnumFold to ignore case.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key, the value, and the allowed values if the value is not allowed, or
+// an error if the file cannot be opened or read.
+func GetEnvEnum(key, filename string, allowed []string) (string, error) {
+	return getEnvEnum(key, filename, allowed, func(a, b string) bool { return a == b })
+}
+
+// GetEnvEnumFold behaves like GetEnvEnum but matches the value against
+// allowed case-insensitively using strings.EqualFold. It returns the matching
+// entry of allowed, so "DEBUG" checked against "debug" yields "debug".
+func GetEnvEnumFold(key, filename string, allowed []string) (string, error) {
+	return getEnvEnum(key, filename, allowed, strings.EqualFold)
+}
+
+// getEnvEnum implements GetEnvEnum and GetEnvEnumFold using equal to match
+// values.
+func getEnvEnum(key, filename string, allowed []string, equal func(a, b string) bool) (string, error) {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return "", err
+	}
+
+	for _, a := range allowed {
+		if equal(value, a) {
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("key %s: %q is not one of %s", key, value, strings.Join(allowed, ", "))
+}
+
+// GetEnvBase64 retrieves the value of key from the given file and decodes it
+// with standard, padded base64, which suits binary secrets such as
+// certificates. Use GetEnvBase64URL for the URL-safe alphabet.
+//
+// Returns an error wrapping ErrNotFound if the key is missing, an error naming
+// the key and wrapping the base64 error if decoding fails, or an error if the
+// file cannot be opened or read. Decoding errors do not include the value,
+// which is likely secret.
+func GetEnvBase64(key, filename string) ([]byte, error) {
+	return getEnvBase64(key, filename, base64.StdEncoding)
+}
+
+// GetEnvBase64URL behaves like GetEnvBase64 but decodes the URL-safe base64
+// alphabet of RFC 4648, with or without trailing padding.
+func GetEnvBase64URL(key, filename string) ([]byte, error) {
+	return getEnvBase64(key, filename, base64.RawURLEncoding)
+}
+
+// getEnvBase64 implements GetEnvBase64 and GetEnvBase64URL. Padding is
+// removed before decoding with an unpadded encoding.
+func getEnvBase64(key, filename string, enc *base64.Encoding) ([]byte, error) {
+	value, err := lookupRequired(key, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if enc == base64.RawURLEncoding {
+		value = strings.TrimRight(value, "=")
+	}
+	data, err := enc.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: cannot decode value as base64: %w", key, err)
+	}
+	return data, nil
+}
+
+// GetEnvOr retrieves the value of key from the given file and converts it with
+// parse, returning def when the key is not present. This covers any type for
+// which a converter exists:
+//
+//	port, err := env.GetEnvOr("PORT", ".env", 8080, strconv.Atoi)
+//
+// The default applies only to missing keys; a key set to an empty string is
+// passed to parse like any other value.
+//
+// Returns an error naming the key and wrapping the error from parse if
+// conversion fails, or an error if the file cannot be opened or read.
+func GetEnvOr[T any](key, filename string, def T, parse func(string) (T, error)) (T, error) {
+	value, found, err := LookupEnv(key, filename)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if !found {
+		return def, nil
+	}
+
+	v, err := parse(value)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("key %s: %w", key, err)
+	}
+	return v, nil
+}
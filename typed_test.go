@@ -0,0 +1,620 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetEnvInt(t *testing.T) {
+	filename, err := createTempEnvFile(`INT_PORT=5432
+INT_NEGATIVE=-10
+INT_INVALID=abc
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name         string
+		key          string
+		wantVal      int
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "valid integer", key: "INT_PORT", wantVal: 5432},
+		{name: "negative integer", key: "INT_NEGATIVE", wantVal: -10},
+		{name: "invalid integer", key: "INT_INVALID", wantErr: true},
+		{name: "missing key", key: "INT_MISSING", wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEnvInt(tt.key, filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnvInt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvInt() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if got != tt.wantVal {
+				t.Errorf("GetEnvInt() = %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestGetEnvIntRange(t *testing.T) {
+	filename, err := createTempEnvFile(`RANGE_PORT=8080
+RANGE_LOW=0
+RANGE_HIGH=65536
+RANGE_INVALID=abc
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name         string
+		key          string
+		wantVal      int
+		wantErr      string
+		wantNotFound bool
+	}{
+		{name: "within range", key: "RANGE_PORT", wantVal: 8080},
+		{name: "below minimum", key: "RANGE_LOW", wantErr: "key RANGE_LOW: 0 is outside the allowed range [1, 65535]"},
+		{name: "above maximum", key: "RANGE_HIGH", wantErr: "key RANGE_HIGH: 65536 is outside the allowed range [1, 65535]"},
+		{name: "invalid integer", key: "RANGE_INVALID", wantErr: `key RANGE_INVALID: cannot parse "abc" as int`},
+		{name: "missing key", key: "RANGE_MISSING", wantErr: "key not found: RANGE_MISSING", wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEnvIntRange(tt.key, filename, 1, 65535)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("GetEnvIntRange() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("GetEnvIntRange() error = %v", err)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvIntRange() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if got != tt.wantVal {
+				t.Errorf("GetEnvIntRange() = %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestGetEnvBool(t *testing.T) {
+	filename, err := createTempEnvFile(`BOOL_TRUE=TRUE
+BOOL_ONE=1
+BOOL_YES=yes
+BOOL_ON=On
+BOOL_FALSE=false
+BOOL_ZERO=0
+BOOL_NO=No
+BOOL_OFF=off
+BOOL_INVALID=maybe
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name         string
+		key          string
+		wantVal      bool
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "true", key: "BOOL_TRUE", wantVal: true},
+		{name: "one", key: "BOOL_ONE", wantVal: true},
+		{name: "yes", key: "BOOL_YES", wantVal: true},
+		{name: "on", key: "BOOL_ON", wantVal: true},
+		{name: "false", key: "BOOL_FALSE", wantVal: false},
+		{name: "zero", key: "BOOL_ZERO", wantVal: false},
+		{name: "no", key: "BOOL_NO", wantVal: false},
+		{name: "off", key: "BOOL_OFF", wantVal: false},
+		{name: "invalid", key: "BOOL_INVALID", wantErr: true},
+		{name: "missing key", key: "BOOL_MISSING", wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEnvBool(tt.key, filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnvBool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvBool() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if got != tt.wantVal {
+				t.Errorf("GetEnvBool() = %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestGetEnvDuration(t *testing.T) {
+	filename, err := createTempEnvFile(`DURATION_TIMEOUT=30s
+DURATION_INTERVAL=1h15m
+DURATION_INVALID=30
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name         string
+		key          string
+		wantVal      time.Duration
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "seconds", key: "DURATION_TIMEOUT", wantVal: 30 * time.Second},
+		{name: "compound", key: "DURATION_INTERVAL", wantVal: time.Hour + 15*time.Minute},
+		{name: "missing unit", key: "DURATION_INVALID", wantErr: true},
+		{name: "missing key", key: "DURATION_MISSING", wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEnvDuration(tt.key, filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnvDuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvDuration() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if got != tt.wantVal {
+				t.Errorf("GetEnvDuration() = %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestGetEnvTime(t *testing.T) {
+	filename, err := createTempEnvFile(`TIME_START=2024-01-01T00:00:00Z
+TIME_OFFSET=2024-06-30T12:30:00+02:00
+TIME_DATE=2024-01-01
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name         string
+		key          string
+		layout       string
+		wantVal      time.Time
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "UTC timestamp", key: "TIME_START", wantVal: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "offset timestamp", key: "TIME_OFFSET", wantVal: time.Date(2024, 6, 30, 10, 30, 0, 0, time.UTC)},
+		{name: "date without layout", key: "TIME_DATE", wantErr: true},
+		{name: "date with layout", key: "TIME_DATE", layout: "2006-01-02", wantVal: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "missing key", key: "TIME_MISSING", wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got time.Time
+			var err error
+			if tt.layout == "" {
+				got, err = GetEnvTime(tt.key, filename)
+			} else {
+				got, err = GetEnvTimeLayout(tt.key, filename, tt.layout)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnvTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvTime() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if !got.Equal(tt.wantVal) {
+				t.Errorf("GetEnvTime() = %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+
+	_, err = GetEnvTime("TIME_DATE", filename)
+	if err == nil || !strings.Contains(err.Error(), time.RFC3339) {
+		t.Errorf("GetEnvTime() error = %v, want error naming the expected format", err)
+	}
+}
+
+func TestGetEnvFloat(t *testing.T) {
+	filename, err := createTempEnvFile(`FLOAT_RATE=0.25
+FLOAT_WHOLE=3
+FLOAT_INVALID=quarter
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name         string
+		key          string
+		wantVal      float64
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "fraction", key: "FLOAT_RATE", wantVal: 0.25},
+		{name: "whole number", key: "FLOAT_WHOLE", wantVal: 3},
+		{name: "invalid", key: "FLOAT_INVALID", wantErr: true},
+		{name: "missing key", key: "FLOAT_MISSING", wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEnvFloat(tt.key, filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnvFloat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvFloat() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if got != tt.wantVal {
+				t.Errorf("GetEnvFloat() = %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestGetEnvBytes(t *testing.T) {
+	filename, err := createTempEnvFile(`BYTES_PLAIN=512
+BYTES_MB=256MB
+BYTES_MIB=256MiB
+BYTES_SPACED=2 gb
+BYTES_FRACTION=1.5KiB
+BYTES_UNKNOWN=10XB
+BYTES_NO_NUMBER=MB
+BYTES_OVERFLOW=9000000TiB
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name         string
+		key          string
+		wantVal      int64
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "bare number", key: "BYTES_PLAIN", wantVal: 512},
+		{name: "decimal unit", key: "BYTES_MB", wantVal: 256 * 1000 * 1000},
+		{name: "binary unit", key: "BYTES_MIB", wantVal: 256 << 20},
+		{name: "space and lower case", key: "BYTES_SPACED", wantVal: 2 * 1000 * 1000 * 1000},
+		{name: "fractional value", key: "BYTES_FRACTION", wantVal: 1536},
+		{name: "unknown unit", key: "BYTES_UNKNOWN", wantErr: true},
+		{name: "missing number", key: "BYTES_NO_NUMBER", wantErr: true},
+		{name: "overflow", key: "BYTES_OVERFLOW", wantErr: true},
+		{name: "missing key", key: "BYTES_MISSING", wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEnvBytes(tt.key, filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnvBytes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvBytes() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if got != tt.wantVal {
+				t.Errorf("GetEnvBytes() = %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestGetEnvSlice(t *testing.T) {
+	filename, err := createTempEnvFile(`SLICE_ORIGINS=a.com, b.com ,c.com
+SLICE_SINGLE=a.com
+SLICE_EMPTY=
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name    string
+		key     string
+		wantVal []string
+	}{
+		{name: "multiple elements", key: "SLICE_ORIGINS", wantVal: []string{"a.com", "b.com", "c.com"}},
+		{name: "single element", key: "SLICE_SINGLE", wantVal: []string{"a.com"}},
+		{name: "empty value", key: "SLICE_EMPTY", wantVal: []string{}},
+		{name: "missing key", key: "SLICE_MISSING", wantVal: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEnvSlice(tt.key, filename, ",")
+			if err != nil {
+				t.Fatalf("GetEnvSlice() error = %v", err)
+			}
+			if got == nil || strings.Join(got, "|") != strings.Join(tt.wantVal, "|") || len(got) != len(tt.wantVal) {
+				t.Errorf("GetEnvSlice() = %q, want %q", got, tt.wantVal)
+			}
+		})
+	}
+
+	if _, err := GetEnvSlice("SLICE_ORIGINS", filename, ""); err == nil {
+		t.Error("GetEnvSlice() expected error for empty separator")
+	}
+}
+
+func TestGetEnvCSV(t *testing.T) {
+	filename, err := createTempEnvFile(`CSV_NAMES="a,b",c
+CSV_COMMENT="a,b",c # names
+CSV_SPACED=a, "b,c", d
+CSV_SINGLE_QUOTED='x,"y ""z""",w'
+CSV_EMPTY=
+CSV_BAD_QUOTE='a,"b'
+CSV_MULTILINE="a\nb"
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name    string
+		key     string
+		wantVal []string
+		wantErr bool
+	}{
+		{name: "quoted element", key: "CSV_NAMES", wantVal: []string{"a,b", "c"}},
+		{name: "inline comment", key: "CSV_COMMENT", wantVal: []string{"a,b", "c"}},
+		{name: "leading spaces", key: "CSV_SPACED", wantVal: []string{"a", "b,c", "d"}},
+		{name: "escaped quotes", key: "CSV_SINGLE_QUOTED", wantVal: []string{"x", `y "z"`, "w"}},
+		{name: "empty value", key: "CSV_EMPTY", wantVal: []string{}},
+		{name: "missing key", key: "CSV_MISSING", wantVal: []string{}},
+		{name: "unterminated quote", key: "CSV_BAD_QUOTE", wantErr: true},
+		{name: "more than one record", key: "CSV_MULTILINE", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEnvCSV(tt.key, filename)
+			if tt.wantErr {
+				if err == nil || !strings.HasPrefix(err.Error(), "key "+tt.key+": malformed CSV: ") {
+					t.Errorf("GetEnvCSV() error = %v, want malformed CSV error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetEnvCSV() error = %v", err)
+			}
+			if got == nil || strings.Join(got, "|") != strings.Join(tt.wantVal, "|") || len(got) != len(tt.wantVal) {
+				t.Errorf("GetEnvCSV() = %q, want %q", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestGetEnvJSON(t *testing.T) {
+	filename, err := createTempEnvFile(`JSON_FLAGS={"a":true,"b":false}
+JSON_INVALID={"a":
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	var flags map[string]bool
+	if err := GetEnvJSON("JSON_FLAGS", filename, &flags); err != nil {
+		t.Fatalf("GetEnvJSON() error = %v", err)
+	}
+	if !flags["a"] || flags["b"] || len(flags) != 2 {
+		t.Errorf("GetEnvJSON() = %v, want map[a:true b:false]", flags)
+	}
+
+	var syntaxErr *json.SyntaxError
+	err = GetEnvJSON("JSON_INVALID", filename, &flags)
+	if !errors.As(err, &syntaxErr) || !strings.Contains(err.Error(), "JSON_INVALID") {
+		t.Errorf("GetEnvJSON() error = %v, want wrapped json error naming the key", err)
+	}
+
+	if err := GetEnvJSON("JSON_MISSING", filename, &flags); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetEnvJSON() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestGetEnvURL(t *testing.T) {
+	filename, err := createTempEnvFile(`URL_API=https://api.example.com/v1
+URL_RELATIVE=/v1/users
+URL_NO_SCHEME=localhost:8080
+URL_INVALID=http://[::1
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name         string
+		key          string
+		wantVal      string
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "absolute URL", key: "URL_API", wantVal: "https://api.example.com/v1"},
+		{name: "relative URL", key: "URL_RELATIVE", wantErr: true},
+		{name: "missing scheme", key: "URL_NO_SCHEME", wantErr: true},
+		{name: "invalid URL", key: "URL_INVALID", wantErr: true},
+		{name: "missing key", key: "URL_MISSING", wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEnvURL(tt.key, filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnvURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvURL() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if err == nil && got.String() != tt.wantVal {
+				t.Errorf("GetEnvURL() = %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestGetEnvEnum(t *testing.T) {
+	filename, err := createTempEnvFile(`ENUM_LEVEL=debug
+ENUM_UPPER=WARN
+ENUM_TYPO=debgu
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	allowed := []string{"debug", "info", "warn"}
+	tests := []struct {
+		name         string
+		key          string
+		fold         bool
+		wantVal      string
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "allowed value", key: "ENUM_LEVEL", wantVal: "debug"},
+		{name: "case mismatch", key: "ENUM_UPPER", wantErr: true},
+		{name: "case mismatch folded", key: "ENUM_UPPER", fold: true, wantVal: "warn"},
+		{name: "disallowed value", key: "ENUM_TYPO", fold: true, wantErr: true},
+		{name: "missing key", key: "ENUM_MISSING", wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get := GetEnvEnum
+			if tt.fold {
+				get = GetEnvEnumFold
+			}
+			got, err := get(tt.key, filename, allowed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnvEnum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvEnum() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if got != tt.wantVal {
+				t.Errorf("GetEnvEnum() = %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+
+	_, err = GetEnvEnum("ENUM_TYPO", filename, allowed)
+	if err == nil || !strings.Contains(err.Error(), "debug, info, warn") {
+		t.Errorf("GetEnvEnum() error = %v, want error listing allowed values", err)
+	}
+}
+
+func TestGetEnvBase64(t *testing.T) {
+	filename, err := createTempEnvFile(`B64_STD=aGk/Pz8+
+B64_URL=aGk_Pz8-
+B64_URL_PADDED=aGk=
+B64_INVALID=not*base64
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name         string
+		key          string
+		url          bool
+		wantVal      string
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "standard alphabet", key: "B64_STD", wantVal: "hi???>"},
+		{name: "URL-safe value with standard alphabet", key: "B64_URL", wantErr: true},
+		{name: "URL-safe alphabet", key: "B64_URL", url: true, wantVal: "hi???>"},
+		{name: "URL-safe with padding", key: "B64_URL_PADDED", url: true, wantVal: "hi"},
+		{name: "invalid value", key: "B64_INVALID", wantErr: true},
+		{name: "missing key", key: "B64_MISSING", wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get := GetEnvBase64
+			if tt.url {
+				get = GetEnvBase64URL
+			}
+			got, err := get(tt.key, filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnvBase64() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GetEnvBase64() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if string(got) != tt.wantVal {
+				t.Errorf("GetEnvBase64() = %q, want %q", got, tt.wantVal)
+			}
+		})
+	}
+
+	_, err = GetEnvBase64("B64_INVALID", filename)
+	if err == nil || strings.Contains(err.Error(), "not*base64") {
+		t.Errorf("GetEnvBase64() error = %v, want error without the value", err)
+	}
+}
+
+func TestGetEnvOr(t *testing.T) {
+	filename, err := createTempEnvFile(`OR_PORT=5432
+OR_INVALID=abc
+OR_TIMEOUT=5s
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	port, err := GetEnvOr("OR_PORT", filename, 8080, strconv.Atoi)
+	if err != nil || port != 5432 {
+		t.Errorf("GetEnvOr() = %v, %v, want 5432, nil", port, err)
+	}
+
+	port, err = GetEnvOr("OR_MISSING", filename, 8080, strconv.Atoi)
+	if err != nil || port != 8080 {
+		t.Errorf("GetEnvOr() = %v, %v, want 8080, nil", port, err)
+	}
+
+	var numErr *strconv.NumError
+	_, err = GetEnvOr("OR_INVALID", filename, 8080, strconv.Atoi)
+	if !errors.As(err, &numErr) || !strings.Contains(err.Error(), "OR_INVALID") {
+		t.Errorf("GetEnvOr() error = %v, want wrapped parse error naming the key", err)
+	}
+
+	timeout, err := GetEnvOr("OR_TIMEOUT", filename, time.Second, time.ParseDuration)
+	if err != nil || timeout != 5*time.Second {
+		t.Errorf("GetEnvOr() = %v, %v, want 5s, nil", timeout, err)
+	}
+
+	if _, err := GetEnvOr("OR_PORT", "non_existent_file.env", 8080, strconv.Atoi); err == nil {
+		t.Error("GetEnvOr() expected error for non-existent file")
+	}
+}
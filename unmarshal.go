@@ -0,0 +1,242 @@
+package env
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal parses the given file and stores the values in the struct pointed
+// to by v. Struct fields are matched to keys through the "env" tag:
+//
+//	type Config struct {
+//		Host  string  `env:"DB_HOST"`
+//		Port  int     `env:"DB_PORT"`
+//		Debug bool    `env:"DEBUG"`
+//		Rate  float64 `env:"SAMPLE_RATE"`
+//	}
+//
+// Supported field types are string, int, int64, bool, and float64, as well as
+// any type whose pointer implements encoding.TextUnmarshaler, such as
+// time.Time or net.IP; UnmarshalText takes precedence over the built-in
+// conversions. Booleans accept the same forms as GetEnvBool, such as "yes" and
+// "off". Fields without a tag, tagged "-", or without a matching key in the
+// file are left unchanged.
+//
+// A struct field tagged with the prefix option instead receives the keys that
+// start with the prefix, matched against the tags of its own fields:
+//
+//	type Config struct {
+//		Database struct {
+//			Host string `env:"HOST"`
+//			Port int    `env:"PORT"`
+//		} `env:",prefix=DB_"`
+//	}
+//
+// populates Database.Host and Database.Port from DB_HOST and DB_PORT.
+// Prefixes compose, so a struct tagged prefix=PRIMARY_ inside Database reads
+// DB_PRIMARY_HOST.
+//
+// The required option, as in `env:"DB_HOST,required"`, makes a key mandatory:
+// every required key absent from the file is listed in a single error. A key
+// present with an empty value satisfies it.
+//
+// The default option, as in `env:"PORT,default=8080"`, supplies the value to
+// use when the key is absent, converted like a value from the file. Since
+// everything after "default=" is the default, it may contain commas and must
+// be the last option. An invalid default is reported even when the key is
+// present, and a default makes the required option redundant.
+//
+// Returns an error if v is not a non-nil pointer to a struct, if the file
+// cannot be opened or read, if a value cannot be converted to its field's
+// type, or if required keys are missing. Conversion errors name both the
+// field and the key.
+func Unmarshal(filename string, v interface{}) error {
+	_, err := unmarshal(filename, v)
+	return err
+}
+
+// UnmarshalStrict behaves like Unmarshal but also returns an error listing
+// the keys in the file that no struct field reads, which catches typos such
+// as DB_HOSTT and configuration drift. Fields are still populated when the
+// only problem is unknown keys.
+func UnmarshalStrict(filename string, v interface{}) error {
+	d, err := unmarshal(filename, v)
+	if err != nil {
+		return err
+	}
+
+	var unknown []string
+	for key := range d.vars {
+		if !d.known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// unmarshal implements Unmarshal and returns the decoder state for further
+// checks.
+func unmarshal(filename string, v interface{}) (*decoder, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("unmarshal target must be a non-nil pointer to a struct")
+	}
+
+	vars, err := Parse(filename)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{vars: vars, known: make(map[string]bool)}
+	if err := d.decodeStruct(rv.Elem(), "", ""); err != nil {
+		return nil, err
+	}
+	if len(d.missing) > 0 {
+		return nil, fmt.Errorf("missing required keys: %s", strings.Join(d.missing, ", "))
+	}
+	return d, nil
+}
+
+// decoder holds the state of a single Unmarshal call.
+type decoder struct {
+	vars map[string]string
+	// known records every key read by a struct field.
+	known map[string]bool
+	// missing lists required keys absent from vars, in field order.
+	missing []string
+}
+
+// decodeStruct stores vars in the fields of the struct rv. Keys are looked up
+// with prefix prepended, and field names in errors with path prepended.
+func (d *decoder) decodeStruct(rv reflect.Value, prefix, path string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("env")
+		if !ok || tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		key, opts := parseTag(tag)
+		if opts.nested {
+			if field.Type.Kind() != reflect.Struct {
+				return fmt.Errorf("field %s%s: prefix option requires a struct, not %s", path, field.Name, field.Type)
+			}
+			if err := d.decodeStruct(rv.Field(i), prefix+opts.prefix, path+field.Name+"."); err != nil {
+				return err
+			}
+			continue
+		}
+		if key == "" {
+			continue
+		}
+
+		key = prefix + key
+		d.known[key] = true
+		if opts.hasDefault {
+			if err := setField(reflect.New(field.Type).Elem(), opts.def); err != nil {
+				return fmt.Errorf("field %s%s (key %s): invalid default: %w", path, field.Name, key, err)
+			}
+		}
+
+		raw, ok := d.vars[key]
+		switch {
+		case ok:
+		case opts.hasDefault:
+			raw = opts.def
+		case opts.required:
+			d.missing = append(d.missing, key)
+			continue
+		default:
+			continue
+		}
+		if err := setField(rv.Field(i), raw); err != nil {
+			return fmt.Errorf("field %s%s (key %s): %w", path, field.Name, key, err)
+		}
+	}
+
+	return nil
+}
+
+// tagOptions holds the options that follow the key in an env struct tag.
+type tagOptions struct {
+	// nested reports whether a prefix option was given, marking a struct
+	// field whose own fields are read with prefix prepended to their keys.
+	nested bool
+	prefix string
+	// required reports whether the key must be present in the file.
+	required bool
+	// hasDefault reports whether def holds a value for an absent key.
+	hasDefault bool
+	def        string
+}
+
+// parseTag splits an env struct tag such as "KEY" or ",prefix=DB_" into the
+// key and its options. A default option takes the rest of the tag, commas
+// included. Unknown options are ignored.
+func parseTag(tag string) (string, tagOptions) {
+	key, rest, _ := strings.Cut(tag, ",")
+	var opts tagOptions
+	for rest != "" {
+		if def, ok := strings.CutPrefix(rest, "default="); ok {
+			opts.hasDefault, opts.def = true, def
+			break
+		}
+		var opt string
+		opt, rest, _ = strings.Cut(rest, ",")
+		switch p, ok := strings.CutPrefix(opt, "prefix="); {
+		case ok:
+			opts.nested, opts.prefix = true, p
+		case opt == "required":
+			opts.required = true
+		}
+	}
+	return key, opts
+}
+
+// setField converts raw to the type of field and assigns it. Fields whose
+// pointer implements encoding.TextUnmarshaler are decoded with UnmarshalText.
+func setField(field reflect.Value, raw string) error {
+	if field.CanAddr() {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(raw)); err != nil {
+				return fmt.Errorf("cannot parse %q as %s: %w", raw, field.Type(), err)
+			}
+			return nil
+		}
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as %s", raw, field.Type())
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		b, ok := parseBool(raw)
+		if !ok {
+			return fmt.Errorf("cannot parse %q as bool", raw)
+		}
+		field.SetBool(b)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as float64", raw)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package env
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnmarshal(t *testing.T) {
+	type config struct {
+		Host    string  `env:"DB_HOST"`
+		Port    int     `env:"DB_PORT"`
+		MaxConn int64   `env:"DB_MAX_CONN"`
+		Debug   bool    `env:"DEBUG"`
+		Verbose bool    `env:"VERBOSE"`
+		Rate    float64 `env:"SAMPLE_RATE"`
+		Missing string  `env:"MISSING_KEY"`
+		Ignored string  `env:"-"`
+		NoTag   string
+	}
+
+	filename, err := createTempEnvFile(`DB_HOST=localhost
+DB_PORT=5432
+DB_MAX_CONN=100
+DEBUG=true
+VERBOSE=yes
+SAMPLE_RATE=0.25
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	var got config
+	if err := Unmarshal(filename, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := config{Host: "localhost", Port: 5432, MaxConn: 100, Debug: true, Verbose: true, Rate: 0.25}
+	if got != want {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	filename, err := createTempEnvFile(`DB_PORT=abc
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	var cfg struct {
+		Port int `env:"DB_PORT"`
+	}
+	err = Unmarshal(filename, &cfg)
+	wantErr := `field Port (key DB_PORT): cannot parse "abc" as int`
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Unmarshal() error = %v, want %v", err, wantErr)
+	}
+
+	if err := Unmarshal(filename, cfg); err == nil {
+		t.Error("Unmarshal() expected error for non-pointer target")
+	}
+	if err := Unmarshal("non_existent_file.env", &cfg); err == nil {
+		t.Error("Unmarshal() expected error for non-existent file")
+	}
+}
+
+type logLevel int
+
+func (l *logLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+func TestUnmarshalTextUnmarshaler(t *testing.T) {
+	filename, err := createTempEnvFile(`LOG_LEVEL=info
+BIND_IP=127.0.0.1
+START=2024-01-01T00:00:00Z
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	var cfg struct {
+		Level logLevel  `env:"LOG_LEVEL"`
+		IP    net.IP    `env:"BIND_IP"`
+		Start time.Time `env:"START"`
+	}
+	if err := Unmarshal(filename, &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if cfg.Level != 1 {
+		t.Errorf("Level = %v, want 1", cfg.Level)
+	}
+	if !cfg.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("IP = %v, want 127.0.0.1", cfg.IP)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !cfg.Start.Equal(want) {
+		t.Errorf("Start = %v, want %v", cfg.Start, want)
+	}
+
+	invalid, err := createTempEnvFile(`LOG_LEVEL=verbose
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(invalid)
+
+	err = Unmarshal(invalid, &cfg)
+	if err == nil || !strings.Contains(err.Error(), "field Level (key LOG_LEVEL)") {
+		t.Errorf("Unmarshal() error = %v, want error naming field and key", err)
+	}
+}
+
+func TestUnmarshalPrefix(t *testing.T) {
+	type replica struct {
+		Host string `env:"HOST"`
+	}
+	type database struct {
+		Host    string  `env:"HOST"`
+		Port    int     `env:"PORT"`
+		Replica replica `env:",prefix=REPLICA_"`
+	}
+	type config struct {
+		Name     string   `env:"APP_NAME"`
+		Database database `env:",prefix=DB_"`
+	}
+
+	filename, err := createTempEnvFile(`APP_NAME=api
+DB_HOST=localhost
+DB_PORT=5432
+DB_REPLICA_HOST=replica.local
+HOST=ignored
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	var got config
+	if err := Unmarshal(filename, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := config{
+		Name: "api",
+		Database: database{
+			Host:    "localhost",
+			Port:    5432,
+			Replica: replica{Host: "replica.local"},
+		},
+	}
+	if got != want {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+
+	invalid, err := createTempEnvFile(`DB_PORT=abc
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(invalid)
+
+	err = Unmarshal(invalid, &got)
+	wantErr := `field Database.Port (key DB_PORT): cannot parse "abc" as int`
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Unmarshal() error = %v, want %v", err, wantErr)
+	}
+
+	var notStruct struct {
+		Port int `env:",prefix=DB_"`
+	}
+	if err := Unmarshal(filename, &notStruct); err == nil {
+		t.Error("Unmarshal() expected error for prefix option on non-struct field")
+	}
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	type config struct {
+		Name     string `env:"APP_NAME"`
+		Database struct {
+			Host string `env:"HOST"`
+		} `env:",prefix=DB_"`
+		Unset string `env:"APP_UNSET"`
+	}
+
+	filename, err := createTempEnvFile(`APP_NAME=api
+DB_HOST=localhost
+DB_HOSTT=typo
+EXTRA=1
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	var got config
+	err = UnmarshalStrict(filename, &got)
+	wantErr := "unknown keys: DB_HOSTT, EXTRA"
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("UnmarshalStrict() error = %v, want %v", err, wantErr)
+	}
+	if got.Name != "api" || got.Database.Host != "localhost" {
+		t.Errorf("UnmarshalStrict() = %+v, want fields populated", got)
+	}
+
+	if err := Unmarshal(filename, &got); err != nil {
+		t.Errorf("Unmarshal() error = %v, want nil for unknown keys", err)
+	}
+
+	known, err := createTempEnvFile("APP_NAME=api\nDB_HOST=localhost\n")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(known)
+	if err := UnmarshalStrict(known, &got); err != nil {
+		t.Errorf("UnmarshalStrict() error = %v, want nil", err)
+	}
+}
+
+func TestUnmarshalRequired(t *testing.T) {
+	type config struct {
+		Host     string `env:"DB_HOST,required"`
+		Port     int    `env:"DB_PORT,required"`
+		User     string `env:"DB_USER,required"`
+		Optional string `env:"DB_OPTIONAL"`
+		Replica  struct {
+			Host string `env:"HOST,required"`
+		} `env:",prefix=REPLICA_"`
+	}
+
+	filename, err := createTempEnvFile(`DB_PORT=5432
+DB_USER=
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	var got config
+	err = Unmarshal(filename, &got)
+	wantErr := "missing required keys: DB_HOST, REPLICA_HOST"
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Unmarshal() error = %v, want %v", err, wantErr)
+	}
+
+	complete, err := createTempEnvFile(`DB_HOST=localhost
+DB_PORT=5432
+DB_USER=admin
+REPLICA_HOST=replica
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(complete)
+
+	if err := Unmarshal(complete, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.Host != "localhost" || got.Port != 5432 || got.User != "admin" || got.Replica.Host != "replica" {
+		t.Errorf("Unmarshal() = %+v, want required fields populated", got)
+	}
+}
+
+func TestUnmarshalDefault(t *testing.T) {
+	filename, err := createTempEnvFile(`DEFAULT_HOST=example.com
+DEFAULT_EMPTY=
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	var cfg struct {
+		Host  string `env:"DEFAULT_HOST,default=localhost"`
+		Port  int    `env:"DEFAULT_PORT,default=8080"`
+		Hosts string `env:"DEFAULT_HOSTS,default=a,b"`
+		Debug bool   `env:"DEFAULT_DEBUG,required,default=true"`
+		Empty string `env:"DEFAULT_EMPTY,default=unused"`
+	}
+	if err := Unmarshal(filename, &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if cfg.Host != "example.com" || cfg.Port != 8080 || cfg.Hosts != "a,b" || !cfg.Debug || cfg.Empty != "" {
+		t.Errorf("Unmarshal() = %+v, want defaults applied to absent keys only", cfg)
+	}
+
+	var invalid struct {
+		Port int `env:"DEFAULT_HOST,default=eighty"`
+	}
+	err = Unmarshal(filename, &invalid)
+	wantErr := `field Port (key DEFAULT_HOST): invalid default: cannot parse "eighty" as int`
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Unmarshal() error = %v, want %v", err, wantErr)
+	}
+}
@@ -0,0 +1,162 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequireEnv parses the given file and checks that every key in keys is
+// present with a non-empty value. All missing or empty keys are reported
+// together in a single error, in the order they were requested, so a broken
+// configuration can be fixed in one pass.
+//
+// Returns an error if the file cannot be opened or read, or if any required
+// key is missing or empty.
+func RequireEnv(filename string, keys ...string) error {
+	vars, err := Parse(filename)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if vars[key] == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required keys: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Type identifies the kind of value a schema field expects.
+type Type int
+
+// Types understood by Validate.
+const (
+	TypeString Type = iota
+	TypeInt
+	TypeBool
+	TypeFloat
+	TypeDuration
+)
+
+// String returns the name of the type as used in validation errors.
+func (t Type) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeInt:
+		return "int"
+	case TypeBool:
+		return "bool"
+	case TypeFloat:
+		return "float64"
+	case TypeDuration:
+		return "duration"
+	}
+	return "Type(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Field describes one expected key in a Schema.
+type Field struct {
+	// Key is the name of the variable.
+	Key string
+
+	// Type is the kind of value the variable must hold. Values are parsed
+	// with the same rules as the matching typed getter, such as GetEnvInt
+	// for TypeInt.
+	Type Type
+
+	// Required marks variables that must be present with a non-empty value.
+	Required bool
+}
+
+// Schema lists the keys a file is expected to define.
+type Schema []Field
+
+// Validate parses the given file and checks it against schema. Required keys
+// must be present with a non-empty value, and every non-empty value must parse
+// as its field's type. Keys in the file that are not in the schema are ignored.
+//
+// All problems are reported together in a single error that names each
+// offending key, in schema order. Returns an error if the file cannot be
+// opened or read.
+func Validate(filename string, schema Schema) error {
+	vars, err := Parse(filename)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, field := range schema {
+		value := vars[field.Key]
+		if value == "" {
+			if field.Required {
+				problems = append(problems, field.Key+": missing required key")
+			}
+			continue
+		}
+		if !checkType(field.Type, value) {
+			problems = append(problems, fmt.Sprintf("%s: cannot parse %q as %s", field.Key, value, field.Type))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkType reports whether value can be parsed as t.
+func checkType(t Type, value string) bool {
+	var err error
+	switch t {
+	case TypeString:
+	case TypeInt:
+		_, err = strconv.Atoi(value)
+	case TypeBool:
+		_, ok := parseBool(value)
+		return ok
+	case TypeFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case TypeDuration:
+		_, err = time.ParseDuration(value)
+	default:
+		return false
+	}
+	return err == nil
+}
+
+// CheckAgainstExample verifies that envFile defines every key listed in
+// exampleFile, such as a committed .env.example. Values are ignored; only the
+// presence of each key matters. All missing keys are reported together, in
+// the order they appear in exampleFile.
+//
+// Returns an error if either file cannot be opened or read, or if any key is
+// missing.
+func CheckAgainstExample(envFile, exampleFile string) error {
+	vars, err := GetAll(envFile)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	err = lookupLoader.parseFile(exampleFile, func(key, _ string, _ int) error {
+		if _, ok := vars[key]; !ok && !seen[key] {
+			missing = append(missing, key)
+		}
+		seen[key] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing keys from %s: %s", exampleFile, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRequireEnv(t *testing.T) {
+	filename, err := createTempEnvFile(`REQUIRE_HOST=localhost
+REQUIRE_EMPTY=
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	tests := []struct {
+		name    string
+		keys    []string
+		wantErr string
+	}{
+		{name: "all present", keys: []string{"REQUIRE_HOST"}},
+		{name: "no keys", keys: nil},
+		{
+			name:    "missing and empty",
+			keys:    []string{"REQUIRE_PORT", "REQUIRE_HOST", "REQUIRE_EMPTY"},
+			wantErr: "missing required keys: REQUIRE_PORT, REQUIRE_EMPTY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RequireEnv(filename, tt.keys...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("RequireEnv() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("RequireEnv() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := RequireEnv("non_existent_file.env", "ANY_KEY"); err == nil {
+		t.Error("RequireEnv() expected error for non-existent file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	filename, err := createTempEnvFile(`DB_HOST=localhost
+DB_PORT=abc
+DEBUG=yes
+SAMPLE_RATE=0.25
+TIMEOUT=30
+OPTIONAL_INT=
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	valid := Schema{
+		{Key: "DB_HOST", Type: TypeString, Required: true},
+		{Key: "DEBUG", Type: TypeBool},
+		{Key: "SAMPLE_RATE", Type: TypeFloat},
+		{Key: "OPTIONAL_INT", Type: TypeInt},
+		{Key: "NOT_IN_FILE", Type: TypeInt},
+	}
+	if err := Validate(filename, valid); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	invalid := Schema{
+		{Key: "DB_HOST", Type: TypeString, Required: true},
+		{Key: "DB_PORT", Type: TypeInt, Required: true},
+		{Key: "API_KEY", Type: TypeString, Required: true},
+		{Key: "TIMEOUT", Type: TypeDuration},
+	}
+	wantErr := `invalid configuration: DB_PORT: cannot parse "abc" as int; API_KEY: missing required key; TIMEOUT: cannot parse "30" as duration`
+	if err := Validate(filename, invalid); err == nil || err.Error() != wantErr {
+		t.Errorf("Validate() error = %v, want %v", err, wantErr)
+	}
+
+	if err := Validate("non_existent_file.env", valid); err == nil {
+		t.Error("Validate() expected error for non-existent file")
+	}
+}
+
+func TestCheckAgainstExample(t *testing.T) {
+	example, err := createTempEnvFile(`DB_HOST=
+DB_PORT=5432
+API_KEY=changeme
+NEW_FEATURE=
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(example)
+
+	complete, err := createTempEnvFile(`DB_HOST=localhost
+DB_PORT=
+API_KEY=secret
+NEW_FEATURE=on
+EXTRA=ignored
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(complete)
+
+	if err := CheckAgainstExample(complete, example); err != nil {
+		t.Errorf("CheckAgainstExample() error = %v, want nil", err)
+	}
+
+	incomplete, err := createTempEnvFile(`DB_PORT=5432
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(incomplete)
+
+	wantErr := "missing keys from " + example + ": DB_HOST, API_KEY, NEW_FEATURE"
+	if err := CheckAgainstExample(incomplete, example); err == nil || err.Error() != wantErr {
+		t.Errorf("CheckAgainstExample() error = %v, want %v", err, wantErr)
+	}
+
+	if err := CheckAgainstExample(complete, "non_existent_file.env"); err == nil {
+		t.Error("CheckAgainstExample() expected error for non-existent example file")
+	}
+}
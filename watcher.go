@@ -0,0 +1,78 @@
+package env
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Watcher detects changes to a .env file by polling its modification time and
+// re-applies the file on request. It lets long-running services pick up
+// configuration changes without a restart:
+//
+//	w, err := env.NewWatcher(".env")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for range time.Tick(10 * time.Second) {
+//		if changed, _ := w.Changed(); changed {
+//			w.Reload()
+//		}
+//	}
+//
+// A Watcher is safe for concurrent use.
+type Watcher struct {
+	filename string
+
+	mu      sync.Mutex
+	modTime time.Time
+}
+
+// NewWatcher returns a Watcher for filename, recording the file's current
+// modification time as the baseline for Changed. It does not load the file.
+//
+// Returns an error if the file cannot be stat'ed.
+func NewWatcher(filename string) (*Watcher, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{filename: filename, modTime: info.ModTime()}, nil
+}
+
+// Changed reports whether the file's modification time differs from the one
+// recorded when the Watcher was created or last reloaded.
+//
+// Returns an error if the file cannot be stat'ed.
+func (w *Watcher) Changed() (bool, error) {
+	info, err := os.Stat(w.filename)
+	if err != nil {
+		return false, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !info.ModTime().Equal(w.modTime), nil
+}
+
+// Reload applies the file with LoadEnv and records its modification time, so
+// Changed reports false until the file is modified again. The time is read
+// before loading, so a write that races with Reload is still reported by the
+// next call to Changed.
+//
+// Returns an error if the file cannot be stat'ed or loaded; the recorded time
+// is left unchanged in that case.
+func (w *Watcher) Reload() error {
+	info, err := os.Stat(w.filename)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := LoadEnv(w.filename); err != nil {
+		return err
+	}
+	w.modTime = info.ModTime()
+	return nil
+}
@@ -0,0 +1,55 @@
+package env
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	filename, err := createTempEnvFile(`WATCH_FLAG=off
+`)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	w, err := NewWatcher(filename)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	changed, err := w.Changed()
+	if err != nil || changed {
+		t.Errorf("Changed() = %v, %v, want false, nil", changed, err)
+	}
+
+	if err := os.WriteFile(filename, []byte("WATCH_FLAG=on\n"), 0600); err != nil {
+		t.Fatalf("failed to rewrite temp file: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filename, future, future); err != nil {
+		t.Fatalf("failed to set modification time: %v", err)
+	}
+
+	changed, err = w.Changed()
+	if err != nil || !changed {
+		t.Errorf("Changed() = %v, %v, want true, nil", changed, err)
+	}
+
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := os.Getenv("WATCH_FLAG"); got != "on" {
+		t.Errorf("WATCH_FLAG = %v, want on", got)
+	}
+
+	changed, err = w.Changed()
+	if err != nil || changed {
+		t.Errorf("Changed() after Reload() = %v, %v, want false, nil", changed, err)
+	}
+
+	if _, err := NewWatcher("non_existent_file.env"); err == nil {
+		t.Error("NewWatcher() expected error for non-existent file")
+	}
+}